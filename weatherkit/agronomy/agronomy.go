@@ -0,0 +1,155 @@
+// Package agronomy computes agricultural weather metrics: degree days, chill
+// hours, frost risk and FAO-56 reference evapotranspiration.
+//
+// Temperatures are in degrees Celsius, relative humidity is a fraction from
+// 0 to 1, wind speeds are in kilometers per hour, radiation is in megajoules
+// per square meter per day and evapotranspiration is in millimeters per day.
+package agronomy
+
+import (
+	"math"
+	"time"
+)
+
+// DegreeDays returns the growing degree days of a day from its minimum and
+// maximum temperatures by the modified average method: both are capped at
+// the upper threshold and the minimum is raised to the base before
+// averaging.
+func DegreeDays(min, max, base, upper float64) float64 {
+	max = math.Min(max, upper)
+	min = math.Min(math.Max(min, base), upper)
+	if max < min {
+		max = min
+	}
+	return math.Max(0, (max+min)/2-base)
+}
+
+// HourlyDegreeDays returns the growing degree days accumulated over hourly
+// temperatures, each counting for 1/24 of a day, with temperatures capped
+// at the upper threshold.
+func HourlyDegreeDays(temperatures []float64, base, upper float64) float64 {
+	var total float64
+	for _, t := range temperatures {
+		total += math.Max(0, math.Min(t, upper)-base) / 24
+	}
+	return total
+}
+
+// Chill hours are counted between these temperatures, following the
+// traditional 45°F model.
+const (
+	ChillMin = 0
+	ChillMax = 7.2
+)
+
+// ChillHours returns the number of hourly temperatures between ChillMin and
+// ChillMax.
+func ChillHours(temperatures []float64) int {
+	var hours int
+	for _, t := range temperatures {
+		if t >= ChillMin && t <= ChillMax {
+			hours++
+		}
+	}
+	return hours
+}
+
+// Frost risk levels.
+const (
+	FrostRiskNone     = "none"
+	FrostRiskLow      = "low"
+	FrostRiskModerate = "moderate"
+	FrostRiskHigh     = "high"
+)
+
+// FrostRisk returns the risk of frost from the minimum air temperature.
+// Ground and leaf temperatures on clear, calm nights can be several degrees
+// below the air temperature, so frost is possible with air temperatures a
+// little above freezing.
+func FrostRisk(min float64) string {
+	switch {
+	case min <= 0:
+		return FrostRiskHigh
+	case min <= 2:
+		return FrostRiskModerate
+	case min <= 4:
+		return FrostRiskLow
+	}
+	return FrostRiskNone
+}
+
+// SaturationVaporPressure returns the saturation vapor pressure in
+// kilopascals at a temperature (FAO-56 equation 11). At the dew point it is
+// the actual vapor pressure.
+func SaturationVaporPressure(t float64) float64 {
+	return 0.6108 * math.Exp(17.27*t/(t+237.3))
+}
+
+// ExtraterrestrialRadiation returns the daily radiation at the top of the
+// atmosphere at a latitude on a date (FAO-56 equation 21).
+func ExtraterrestrialRadiation(latitude float64, date time.Time) float64 {
+	const solarConstant = 0.0820
+	j := float64(date.YearDay())
+	phi := latitude * math.Pi / 180
+	dr := 1 + 0.033*math.Cos(2*math.Pi*j/365)
+	delta := 0.409 * math.Sin(2*math.Pi*j/365-1.39)
+	// Clamp for polar day and night.
+	x := math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(delta)))
+	ws := math.Acos(x)
+	return 24 * 60 / math.Pi * solarConstant * dr * (ws*math.Sin(phi)*math.Sin(delta) + math.Cos(phi)*math.Cos(delta)*math.Sin(ws))
+}
+
+// SolarRadiation estimates the daily radiation reaching the ground from the
+// extraterrestrial radiation and the cloud cover, from 0 to 1, with the
+// Angstrom formula, taking the relative sunshine duration as the fraction of
+// clear sky (FAO-56 equation 35).
+func SolarRadiation(extraterrestrial, cloudCover float64) float64 {
+	return (0.25 + 0.5*(1-cloudCover)) * extraterrestrial
+}
+
+// Day holds the daily inputs of ReferenceEvapotranspiration.
+type Day struct {
+	Date           time.Time
+	Latitude       float64
+	Elevation      float64 // meters
+	TemperatureMin float64
+	TemperatureMax float64
+	VaporPressure  float64 // actual, in kilopascals
+	WindSpeed      float64 // at 10 meters
+	CloudCover     float64
+}
+
+// windSpeedAt2m converts a wind speed at 10 meters in kilometers per hour to
+// the wind speed at 2 meters in meters per second (FAO-56 equation 47).
+func windSpeedAt2m(windSpeed float64) float64 {
+	return windSpeed / 3.6 * 4.87 / math.Log(67.8*10-5.42)
+}
+
+// ReferenceEvapotranspiration returns the FAO-56 Penman-Monteith reference
+// evapotranspiration of a grass surface for a day, with the solar radiation
+// estimated from the cloud cover and the soil heat flux taken as zero.
+func ReferenceEvapotranspiration(d Day) float64 {
+	mean := (d.TemperatureMax + d.TemperatureMin) / 2
+	delta := 4098 * SaturationVaporPressure(mean) / math.Pow(mean+237.3, 2)
+	pressure := 101.3 * math.Pow((293-0.0065*d.Elevation)/293, 5.26)
+	gamma := 0.000665 * pressure
+	u2 := windSpeedAt2m(d.WindSpeed)
+	es := (SaturationVaporPressure(d.TemperatureMax) + SaturationVaporPressure(d.TemperatureMin)) / 2
+	ea := math.Min(d.VaporPressure, es)
+
+	ra := ExtraterrestrialRadiation(d.Latitude, d.Date)
+	rs := SolarRadiation(ra, d.CloudCover)
+	rso := (0.75 + 2e-5*d.Elevation) * ra
+	rns := 0.77 * rs
+	const sigma = 4.903e-9
+	tmaxK, tminK := d.TemperatureMax+273.16, d.TemperatureMin+273.16
+	relative := 1.0
+	if rso > 0 {
+		relative = math.Min(rs/rso, 1)
+	}
+	rnl := sigma * (math.Pow(tmaxK, 4) + math.Pow(tminK, 4)) / 2 * (0.34 - 0.14*math.Sqrt(ea)) * (1.35*relative - 0.35)
+	rn := rns - rnl
+
+	et0 := (0.408*delta*rn + gamma*900/(mean+273)*u2*(es-ea)) / (delta + gamma*(1+0.34*u2))
+	return math.Max(0, et0)
+}
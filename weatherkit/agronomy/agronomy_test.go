@@ -0,0 +1,171 @@
+package agronomy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDegreeDays(t *testing.T) {
+	tests := []struct {
+		min, max, base, upper float64
+		want                  float64
+	}{
+		{10, 20, 10, 30, 5},
+		// The mean exactly at the base, and a day entirely at the base.
+		{5, 15, 10, 30, 2.5},
+		{10, 10, 10, 30, 0},
+		{0, 20, 10, 30, 5},
+		// Days entirely below the base count nothing.
+		{-5, 10, 10, 30, 0},
+		{-5, 5, 10, 30, 0},
+		// The maximum is capped at the upper threshold.
+		{20, 36, 10, 30, 15},
+		{31, 36, 10, 30, 20},
+	}
+	for _, tt := range tests {
+		if got := DegreeDays(tt.min, tt.max, tt.base, tt.upper); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("DegreeDays(%g, %g, %g, %g) = %g, want %g", tt.min, tt.max, tt.base, tt.upper, got, tt.want)
+		}
+	}
+}
+
+func TestHourlyDegreeDays(t *testing.T) {
+	day := make([]float64, 24)
+	for i := range day {
+		day[i] = 20
+	}
+	tests := []struct {
+		temperatures []float64
+		want         float64
+	}{
+		{nil, 0},
+		{day, 10},
+		// Hours exactly at the base count nothing, and hours above the
+		// upper threshold count as the threshold.
+		{[]float64{10, 10, 22, 40}, (12 + 20) / 24.0},
+	}
+	for _, tt := range tests {
+		if got := HourlyDegreeDays(tt.temperatures, 10, 30); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("HourlyDegreeDays(%v) = %g, want %g", tt.temperatures, got, tt.want)
+		}
+	}
+}
+
+func TestChillHours(t *testing.T) {
+	tests := []struct {
+		temperatures []float64
+		want         int
+	}{
+		{nil, 0},
+		// Both bounds count.
+		{[]float64{ChillMin, ChillMax}, 2},
+		{[]float64{-0.1, 0, 3, 7.2, 7.3}, 3},
+		{[]float64{-10, 15}, 0},
+	}
+	for _, tt := range tests {
+		if got := ChillHours(tt.temperatures); got != tt.want {
+			t.Errorf("ChillHours(%v) = %d, want %d", tt.temperatures, got, tt.want)
+		}
+	}
+}
+
+func TestFrostRisk(t *testing.T) {
+	tests := []struct {
+		min  float64
+		want string
+	}{
+		{-5, FrostRiskHigh},
+		{0, FrostRiskHigh},
+		{0.1, FrostRiskModerate},
+		{2, FrostRiskModerate},
+		{2.1, FrostRiskLow},
+		{4, FrostRiskLow},
+		{4.1, FrostRiskNone},
+	}
+	for _, tt := range tests {
+		if got := FrostRisk(tt.min); got != tt.want {
+			t.Errorf("FrostRisk(%g) = %s, want %s", tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestSaturationVaporPressure(t *testing.T) {
+	// FAO-56 Annex 2, Table 2.3.
+	tests := []struct {
+		t, want float64
+	}{
+		{1, 0.657},
+		{12.3, 1.431},
+		{21.5, 2.564},
+		{25, 3.168},
+	}
+	for _, tt := range tests {
+		if got := SaturationVaporPressure(tt.t); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("SaturationVaporPressure(%g) = %.4f, want %g", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestExtraterrestrialRadiation(t *testing.T) {
+	tests := []struct {
+		latitude float64
+		date     time.Time
+		want     float64
+	}{
+		// FAO-56 Example 8, 20°S on 3 September.
+		{-20, time.Date(2023, 9, 3, 0, 0, 0, 0, time.UTC), 32.2},
+		// FAO-56 Example 18, Brussels on 6 July.
+		{50.8, time.Date(2023, 7, 6, 0, 0, 0, 0, time.UTC), 41.09},
+		// Polar night gets no radiation.
+		{80, time.Date(2023, 12, 21, 0, 0, 0, 0, time.UTC), 0},
+	}
+	for _, tt := range tests {
+		if got := ExtraterrestrialRadiation(tt.latitude, tt.date); math.Abs(got-tt.want) > 0.05 {
+			t.Errorf("ExtraterrestrialRadiation(%g, %s) = %.2f, want %g", tt.latitude, tt.date.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
+func TestWindSpeedAt2m(t *testing.T) {
+	tests := []struct {
+		windSpeed, want float64
+	}{
+		// FAO-56 Example 14, 3.2 m/s at 10 meters.
+		{3.2 * 3.6, 2.4},
+		// FAO-56 Example 18, 10 km/h at 10 meters.
+		{10, 2.078},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := windSpeedAt2m(tt.windSpeed); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("windSpeedAt2m(%g) = %.3f, want %g", tt.windSpeed, got, tt.want)
+		}
+	}
+}
+
+func TestReferenceEvapotranspiration(t *testing.T) {
+	// FAO-56 Example 18, Brussels on 6 July, with the 9.25 hours of
+	// sunshine out of 16.1 hours of daylight as the fraction of clear sky.
+	day := Day{
+		Date:           time.Date(2023, 7, 6, 12, 0, 0, 0, time.UTC),
+		Latitude:       50.8,
+		Elevation:      100,
+		TemperatureMin: 12.3,
+		TemperatureMax: 21.5,
+		VaporPressure:  1.409,
+		WindSpeed:      10,
+		CloudCover:     1 - 9.25/16.1,
+	}
+	if got := ReferenceEvapotranspiration(day); math.Abs(got-3.9) > 0.05 {
+		t.Errorf("ReferenceEvapotranspiration(Example 18) = %.2f, want 3.9", got)
+	}
+
+	// Vapor pressure above saturation is capped, so the air is saturated
+	// and only radiation drives evapotranspiration.
+	saturated := day
+	saturated.VaporPressure = 5
+	if got, want := ReferenceEvapotranspiration(saturated), ReferenceEvapotranspiration(day); got <= 0 || got >= want {
+		t.Errorf("ReferenceEvapotranspiration(saturated) = %.2f, want between 0 and %.2f", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+// Package astronomy computes the position of the sun and moon and the times of
+// solar and lunar events.
+//
+// The formulas follow the low precision algorithms from Astronomical
+// Algorithms by Jean Meeus as popularised by the SunCalc library, and are
+// accurate to about a minute for the sun and a few minutes for the moon.
+// Latitudes and longitudes are in degrees, with east and north positive.
+package astronomy
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	rad       = math.Pi / 180
+	j1970     = 2440588.0
+	j2000     = 2451545.0
+	obliquity = rad * 23.4397
+)
+
+func toJulian(t time.Time) float64 {
+	return float64(t.UnixNano())/float64(24*time.Hour) - 0.5 + j1970
+}
+
+func fromJulian(j float64) time.Time {
+	return time.Unix(0, int64((j+0.5-j1970)*float64(24*time.Hour))).UTC()
+}
+
+func toDays(t time.Time) float64 {
+	return toJulian(t) - j2000
+}
+
+func rightAscension(l, b float64) float64 {
+	return math.Atan2(math.Sin(l)*math.Cos(obliquity)-math.Tan(b)*math.Sin(obliquity), math.Cos(l))
+}
+
+func declination(l, b float64) float64 {
+	return math.Asin(math.Sin(b)*math.Cos(obliquity) + math.Cos(b)*math.Sin(obliquity)*math.Sin(l))
+}
+
+// azimuth is measured from south, westward.
+func azimuth(h, phi, dec float64) float64 {
+	return math.Atan2(math.Sin(h), math.Cos(h)*math.Sin(phi)-math.Tan(dec)*math.Cos(phi))
+}
+
+func altitude(h, phi, dec float64) float64 {
+	return math.Asin(math.Sin(phi)*math.Sin(dec) + math.Cos(phi)*math.Cos(dec)*math.Cos(h))
+}
+
+func siderealTime(d, lw float64) float64 {
+	return rad*(280.16+360.9856235*d) - lw
+}
+
+func astroRefraction(h float64) float64 {
+	if h < 0 {
+		h = 0
+	}
+	return 0.0002967 / math.Tan(h+0.00312536/(h+0.08901179))
+}
+
+func solarMeanAnomaly(d float64) float64 {
+	return rad * (357.5291 + 0.98560028*d)
+}
+
+func eclipticLongitude(m float64) float64 {
+	c := rad * (1.9148*math.Sin(m) + 0.02*math.Sin(2*m) + 0.0003*math.Sin(3*m))
+	perihelion := rad * 102.9372
+	return m + c + perihelion + math.Pi
+}
+
+type coords struct {
+	dec, ra, dist float64
+}
+
+func sunCoords(d float64) coords {
+	l := eclipticLongitude(solarMeanAnomaly(d))
+	return coords{dec: declination(l, 0), ra: rightAscension(l, 0)}
+}
+
+func moonCoords(d float64) coords {
+	l := rad * (218.316 + 13.176396*d)
+	m := rad * (134.963 + 13.064993*d)
+	f := rad * (93.272 + 13.229350*d)
+	lng := l + rad*6.289*math.Sin(m)
+	lat := rad * 5.128 * math.Sin(f)
+	return coords{
+		ra:   rightAscension(lng, lat),
+		dec:  declination(lng, lat),
+		dist: 385001 - 20905*math.Cos(m),
+	}
+}
+
+// normalizeAzimuth converts a south based azimuth in radians to a compass
+// bearing in degrees.
+func normalizeAzimuth(a float64) float64 {
+	deg := math.Mod(a/rad+180, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// SunPosition returns the altitude above the horizon and the compass azimuth
+// of the center of the sun, in degrees, without atmospheric refraction.
+func SunPosition(t time.Time, latitude, longitude float64) (alt, az float64) {
+	lw := rad * -longitude
+	phi := rad * latitude
+	d := toDays(t)
+	c := sunCoords(d)
+	h := siderealTime(d, lw) - c.ra
+	return altitude(h, phi, c.dec) / rad, normalizeAzimuth(azimuth(h, phi, c.dec))
+}
+
+// SunDistance returns the distance from the earth to the sun in astronomical
+// units.
+func SunDistance(t time.Time) float64 {
+	m := solarMeanAnomaly(toDays(t))
+	return 1.00014 - 0.01671*math.Cos(m) - 0.00014*math.Cos(2*m)
+}
+
+// MoonPosition returns the altitude, including atmospheric refraction, and the
+// compass azimuth of the moon in degrees, and its distance in kilometers.
+func MoonPosition(t time.Time, latitude, longitude float64) (alt, az, distance float64) {
+	lw := rad * -longitude
+	phi := rad * latitude
+	d := toDays(t)
+	c := moonCoords(d)
+	h := siderealTime(d, lw) - c.ra
+	a := altitude(h, phi, c.dec)
+	a += astroRefraction(a)
+	return a / rad, normalizeAzimuth(azimuth(h, phi, c.dec)), c.dist
+}
+
+// MoonIllumination returns the illuminated fraction of the moon, from 0 to 1,
+// and the moon phase, where 0 is new moon, 0.25 first quarter, 0.5 full moon
+// and 0.75 last quarter.
+func MoonIllumination(t time.Time) (fraction, phase float64) {
+	const sunDistance = 149598000
+	d := toDays(t)
+	s := sunCoords(d)
+	m := moonCoords(d)
+	elongation := math.Acos(math.Sin(s.dec)*math.Sin(m.dec) + math.Cos(s.dec)*math.Cos(m.dec)*math.Cos(s.ra-m.ra))
+	inc := math.Atan2(sunDistance*math.Sin(elongation), m.dist-sunDistance*math.Cos(elongation))
+	angle := math.Atan2(math.Cos(s.dec)*math.Sin(s.ra-m.ra), math.Sin(s.dec)*math.Cos(m.dec)-math.Cos(s.dec)*math.Sin(m.dec)*math.Cos(s.ra-m.ra))
+	sign := 1.0
+	if angle < 0 {
+		sign = -1
+	}
+	return (1 + math.Cos(inc)) / 2, 0.5 + 0.5*inc*sign/math.Pi
+}
+
+// MoonPhaseName returns the WeatherKit name of a moon phase returned by
+// MoonIllumination.
+func MoonPhaseName(phase float64) string {
+	// Each named quarter spans a little over a day either side of the exact phase.
+	const window = 0.0339
+	switch {
+	case phase < window || phase > 1-window:
+		return "new"
+	case phase < 0.25-window:
+		return "waxingCrescent"
+	case phase <= 0.25+window:
+		return "firstQuarter"
+	case phase < 0.5-window:
+		return "waxingGibbous"
+	case phase <= 0.5+window:
+		return "full"
+	case phase < 0.75-window:
+		return "waningGibbous"
+	case phase <= 0.75+window:
+		return "thirdQuarter"
+	default:
+		return "waningCrescent"
+	}
+}
+
+// Solar altitudes, in degrees, that define the solar events.
+const (
+	SunriseAltitude      = -0.833
+	CivilAltitude        = -6
+	NauticalAltitude     = -12
+	AstronomicalAltitude = -18
+	BlueHourAltitude     = -4
+	GoldenHourAltitude   = 6
+)
+
+// SolarDay is the solar noon of a day and the functions to find the times the
+// sun crosses a given altitude either side of it.
+type SolarDay struct {
+	lw, phi, dec, n, m, l float64
+	noon                  float64
+}
+
+// NewSolarDay returns the solar day whose solar noon is nearest to noon local
+// mean time on the given date at the given longitude.
+func NewSolarDay(date time.Time, latitude, longitude float64) SolarDay {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.UTC)
+	lw := rad * -longitude
+	n := math.Round(toDays(date) - lw/(2*math.Pi))
+	// SunCalc adds 0.0009 days here for the difference between terrestrial
+	// and universal time, which puts every event about 80 seconds late.
+	ds := lw/(2*math.Pi) + n
+	m := solarMeanAnomaly(ds)
+	l := eclipticLongitude(m)
+	return SolarDay{
+		lw:   lw,
+		phi:  rad * latitude,
+		dec:  declination(l, 0),
+		n:    n,
+		m:    m,
+		l:    l,
+		noon: solarTransitJ(ds, m, l),
+	}
+}
+
+func solarTransitJ(ds, m, l float64) float64 {
+	return j2000 + ds + 0.0053*math.Sin(m) - 0.0069*math.Sin(2*l)
+}
+
+// SolarNoon returns the time the sun is highest in the sky.
+func (s SolarDay) SolarNoon() time.Time {
+	return fromJulian(s.noon)
+}
+
+// SolarMidnight returns the time the sun is lowest in the sky, after solar noon.
+func (s SolarDay) SolarMidnight() time.Time {
+	return fromJulian(s.noon + 0.5)
+}
+
+// Crossings returns the times the center of the sun rises above and sets
+// below the given altitude in degrees. ok is false when the sun does not
+// cross the altitude that day, as in polar day or night.
+func (s SolarDay) Crossings(alt float64) (rise, set time.Time, ok bool) {
+	jrise, ok := s.crossing(alt, -1)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	jset, ok := s.crossing(alt, 1)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	return fromJulian(jrise), fromJulian(jset), true
+}
+
+// crossing returns the Julian date the sun crosses the altitude before solar
+// noon, for a negative sign, or after it. The first estimate uses the
+// declination at solar noon, and is refined with the declination at that
+// time, which changes by up to 0.4 degrees a day around the equinoxes.
+func (s SolarDay) crossing(alt, sign float64) (float64, bool) {
+	dec := s.dec
+	var j float64
+	for i := 0; i < 2; i++ {
+		cosH := (math.Sin(alt*rad) - math.Sin(s.phi)*math.Sin(dec)) / (math.Cos(s.phi) * math.Cos(dec))
+		if cosH < -1 || cosH > 1 || math.IsNaN(cosH) {
+			return 0, false
+		}
+		w := math.Acos(cosH)
+		a := (w+s.lw)/(2*math.Pi) + s.n
+		j = s.noon + sign*(solarTransitJ(a, s.m, s.l)-s.noon)
+		dec = sunCoords(j - j2000).dec
+	}
+	return j, true
+}
+
+// MoonTimes returns the moonrise and moonset within the 24 hours starting at
+// the given time. Either is nil if the moon does not rise or set in that
+// window.
+func MoonTimes(start time.Time, latitude, longitude float64) (rise, set *time.Time) {
+	// The moon's altitude at rise and set, allowing for parallax and refraction.
+	const horizon = 0.133
+	const step = 10 * time.Minute
+	prev, _, _ := MoonPosition(start, latitude, longitude)
+	prev -= horizon
+	for t := start.Add(step); !t.After(start.Add(24 * time.Hour)); t = t.Add(step) {
+		alt, _, _ := MoonPosition(t, latitude, longitude)
+		alt -= horizon
+		if (prev < 0) != (alt < 0) {
+			crossing := t.Add(-time.Duration(float64(step) * alt / (alt - prev))).UTC()
+			if alt > 0 && rise == nil {
+				rise = &crossing
+			} else if alt < 0 && set == nil {
+				set = &crossing
+			}
+		}
+		prev = alt
+	}
+	return rise, set
+}
@@ -0,0 +1,170 @@
+package astronomy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func utc(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSolarDay(t *testing.T) {
+	// Expected times are those of the NOAA Solar Calculator spreadsheet, in
+	// UTC.
+	tests := []struct {
+		place               string
+		latitude, longitude float64
+		date                string
+		noon                string
+		// Sunrise, civil, nautical and astronomical dawn, then the dusks
+		// in the same order.
+		dawn, dusk [4]string
+	}{
+		{
+			"Greenwich", 51.4769, -0.0005, "2024-06-20", "2024-06-20 12:01:42",
+			[4]string{"2024-06-20 03:42:38", "2024-06-20 02:54:56", "2024-06-20 01:40:29", ""},
+			[4]string{"2024-06-20 20:20:47", "2024-06-20 21:08:30", "2024-06-20 22:22:57", ""},
+		},
+		{
+			"Greenwich", 51.4769, -0.0005, "2024-12-21", "2024-12-21 11:58:19",
+			[4]string{"2024-12-21 08:03:20", "2024-12-21 07:23:02", "2024-12-21 06:39:51", "2024-12-21 05:59:04"},
+			[4]string{"2024-12-21 15:53:18", "2024-12-21 16:33:36", "2024-12-21 17:16:46", "2024-12-21 17:57:34"},
+		},
+		{
+			"New York", 40.7128, -74.006, "2024-03-20", "2024-03-20 17:03:15",
+			[4]string{"2024-03-20 10:58:29", "2024-03-20 10:31:12", "2024-03-20 09:59:15", "2024-03-20 09:26:42"},
+			[4]string{"2024-03-20 23:08:42", "2024-03-20 23:36:03", "2024-03-21 00:08:05", "2024-03-21 00:40:44"},
+		},
+		{
+			"Ann Arbor", 42.2808, -83.743, "2024-09-22", "2024-09-22 17:27:27",
+			[4]string{"2024-09-22 11:22:58", "2024-09-22 10:54:57", "2024-09-22 10:22:08", "2024-09-22 09:48:38"},
+			[4]string{"2024-09-22 23:31:13", "2024-09-22 23:59:10", "2024-09-23 00:31:54", "2024-09-23 01:05:17"},
+		},
+		{
+			"Sydney", -33.8688, 151.2093, "2024-12-21", "2024-12-21 01:53:16",
+			[4]string{"2024-12-20 18:40:52", "2024-12-20 18:11:42", "2024-12-20 17:35:48", "2024-12-20 16:56:28"},
+			[4]string{"2024-12-21 09:05:40", "2024-12-21 09:34:50", "2024-12-21 10:10:45", "2024-12-21 10:50:05"},
+		},
+		{
+			"Quito", -0.1807, -78.4678, "2024-03-20", "2024-03-20 17:21:05",
+			[4]string{"2024-03-20 11:17:50", "2024-03-20 10:57:10", "2024-03-20 10:33:10", "2024-03-20 10:09:11"},
+			[4]string{"2024-03-20 23:24:20", "2024-03-20 23:45:00", "2024-03-21 00:09:00", "2024-03-21 00:33:00"},
+		},
+	}
+	altitudes := [4]float64{SunriseAltitude, CivilAltitude, NauticalAltitude, AstronomicalAltitude}
+	// The low precision formulas are accurate to about a minute.
+	const tolerance = time.Minute
+	for _, tt := range tests {
+		date, err := time.Parse("2006-01-02", tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		day := NewSolarDay(date, tt.latitude, tt.longitude)
+		if got := day.SolarNoon(); got.Sub(utc(tt.noon)).Abs() > tolerance {
+			t.Errorf("%s %s solar noon = %s, want %s", tt.place, tt.date, got.Format(time.RFC3339), tt.noon)
+		}
+		for i, alt := range altitudes {
+			rise, set, ok := day.Crossings(alt)
+			if tt.dawn[i] == "" {
+				// The sun does not go 18 degrees below the horizon at
+				// Greenwich in midsummer.
+				if ok {
+					t.Errorf("%s %s crosses %g degrees at %s and %s, want no crossing", tt.place, tt.date, alt, rise, set)
+				}
+				continue
+			}
+			if !ok {
+				t.Errorf("%s %s does not cross %g degrees", tt.place, tt.date, alt)
+				continue
+			}
+			if rise.Sub(utc(tt.dawn[i])).Abs() > tolerance || set.Sub(utc(tt.dusk[i])).Abs() > tolerance {
+				t.Errorf("%s %s crosses %g degrees at %s and %s, want %s and %s", tt.place, tt.date, alt, rise.Format(time.RFC3339), set.Format(time.RFC3339), tt.dawn[i], tt.dusk[i])
+			}
+		}
+	}
+}
+
+func TestPolarDayAndNight(t *testing.T) {
+	const latitude, longitude = 69.6492, 18.9553 // Tromsø
+
+	// In midsummer the sun does not set.
+	summer := NewSolarDay(time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC), latitude, longitude)
+	if rise, set, ok := summer.Crossings(SunriseAltitude); ok {
+		t.Errorf("Tromsø midsummer sunrise and sunset = %s and %s, want polar day", rise, set)
+	}
+	if alt, _ := SunPosition(summer.SolarMidnight(), latitude, longitude); alt <= SunriseAltitude {
+		t.Errorf("Tromsø midsummer midnight sun altitude = %g, want above the horizon", alt)
+	}
+
+	// In midwinter the sun does not rise, though there is civil twilight
+	// around noon.
+	winter := NewSolarDay(time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), latitude, longitude)
+	if rise, set, ok := winter.Crossings(SunriseAltitude); ok {
+		t.Errorf("Tromsø midwinter sunrise and sunset = %s and %s, want polar night", rise, set)
+	}
+	if alt, _ := SunPosition(winter.SolarNoon(), latitude, longitude); alt >= SunriseAltitude || alt <= CivilAltitude {
+		t.Errorf("Tromsø midwinter noon sun altitude = %g, want between %v and %v", alt, CivilAltitude, SunriseAltitude)
+	}
+	if _, _, ok := winter.Crossings(CivilAltitude); !ok {
+		t.Error("Tromsø midwinter has no civil twilight")
+	}
+}
+
+func TestMoonIllumination(t *testing.T) {
+	// Times of the moon phases are those published by the US Naval
+	// Observatory.
+	tests := []struct {
+		time                string
+		fraction, tolerance float64
+		phase               string
+	}{
+		{"2024-01-04 03:30:00", 0.5, 0.01, "thirdQuarter"},
+		{"2024-01-11 11:57:00", 0, 0.01, "new"},
+		{"2024-01-18 03:53:00", 0.5, 0.01, "firstQuarter"},
+		{"2024-01-25 17:54:00", 1, 0.01, "full"},
+		{"2024-04-08 18:21:00", 0, 0.01, "new"},
+		{"2024-09-18 02:34:00", 1, 0.01, "full"},
+		// Halfway in time between the quarters the phase angle is about 45
+		// degrees from them, and the low precision position of the moon is
+		// a few degrees out.
+		{"2024-01-14 19:55:00", 0.15, 0.05, "waxingCrescent"},
+		{"2024-01-29 08:36:00", 0.85, 0.05, "waningGibbous"},
+	}
+	for _, tt := range tests {
+		fraction, phase := MoonIllumination(utc(tt.time))
+		if math.Abs(fraction-tt.fraction) > tt.tolerance {
+			t.Errorf("moon illumination at %s = %.3f, want %g", tt.time, fraction, tt.fraction)
+		}
+		if name := MoonPhaseName(phase); name != tt.phase {
+			t.Errorf("moon phase at %s = %s (%.3f), want %s", tt.time, name, phase, tt.phase)
+		}
+	}
+}
+
+func TestMoonPhaseName(t *testing.T) {
+	tests := []struct {
+		phase float64
+		want  string
+	}{
+		{0, "new"},
+		{0.99, "new"},
+		{0.1, "waxingCrescent"},
+		{0.25, "firstQuarter"},
+		{0.4, "waxingGibbous"},
+		{0.5, "full"},
+		{0.6, "waningGibbous"},
+		{0.75, "thirdQuarter"},
+		{0.9, "waningCrescent"},
+	}
+	for _, tt := range tests {
+		if got := MoonPhaseName(tt.phase); got != tt.want {
+			t.Errorf("MoonPhaseName(%g) = %s, want %s", tt.phase, got, tt.want)
+		}
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -162,12 +161,24 @@ func (c *Client) Availability(ctx context.Context, latitude float64, longitude f
 
 	err := c.Get(ctx, requestUrl.String(), &dataSet)
 	if err != nil {
-		log.Fatalf("Request failed: [%s]", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	return dataSet, nil
 }
 
 func (c *Client) Weather(ctx context.Context, latitude float64, longitude float64, datasets []string) (Weather, error) {
+	return c.WeatherWithParams(ctx, latitude, longitude, datasets, nil)
+}
+
+// WeatherWithParams requests the datasets with additional query parameters,
+// such as hourlyStart and hourlyEnd.
+func (c *Client) WeatherWithParams(ctx context.Context, latitude float64, longitude float64, datasets []string, params url.Values) (Weather, error) {
+	// Invalid coordinates are rejected here rather than by the API, whose 400
+	// response would panic in checkResponseStatus.
+	if err := validateCoordinates(latitude, longitude); err != nil {
+		return Weather{}, err
+	}
+	longitude = normalizeLongitude(longitude)
 	lat := fmt.Sprintf("%f", latitude)
 	lng := fmt.Sprintf("%f", longitude)
 	requestUrl := url.URL{
@@ -176,6 +187,9 @@ func (c *Client) Weather(ctx context.Context, latitude float64, longitude float6
 		Path:   strings.Join([]string{"api", "v1", "weather", language, lat, lng}, "/"),
 	}
 	u := requestUrl.Query()
+	for key, values := range params {
+		u[key] = values
+	}
 	u.Set("country", "US")
 	u.Set("dataSets", strings.Join(datasets, ","))
 	requestUrl.RawQuery = u.Encode()
@@ -183,10 +197,13 @@ func (c *Client) Weather(ctx context.Context, latitude float64, longitude float6
 	//Response object
 	var weather Weather
 
+	// Errors are returned rather than fatal, since requests for many
+	// locations run concurrently in the plugin process.
 	err := c.Get(ctx, requestUrl.String(), &weather)
 	if err != nil {
-		log.Fatalf("Request failed: [%s]", err)
+		return Weather{}, fmt.Errorf("request failed: %w", err)
 	}
+	c.recordSnapshots(latitude, longitude, weather)
 	return weather, nil
 }
 
@@ -202,6 +219,15 @@ func (c *Client) HourlyForecast(ctx context.Context, latitude float64, longitude
 	return c.Weather(ctx, latitude, longitude, []string{"forecastHourly"})
 }
 
+// HourlyForecastRange requests the hourly forecast for the hours from start
+// to end.
+func (c *Client) HourlyForecastRange(ctx context.Context, latitude float64, longitude float64, start time.Time, end time.Time) (Weather, error) {
+	params := url.Values{}
+	params.Set("hourlyStart", start.UTC().Format(time.RFC3339))
+	params.Set("hourlyEnd", end.UTC().Format(time.RFC3339))
+	return c.WeatherWithParams(ctx, latitude, longitude, []string{"forecastHourly"}, params)
+}
+
 func (c *Client) NextHourForecast(ctx context.Context, latitude float64, longitude float64) (Weather, error) {
 	return c.Weather(ctx, latitude, longitude, []string{"forecastNextHour"})
 }
@@ -209,3 +235,7 @@ func (c *Client) NextHourForecast(ctx context.Context, latitude float64, longitu
 func (c *Client) WeatherAlerts(ctx context.Context, latitude float64, longitude float64) (Weather, error) {
 	return c.Weather(ctx, latitude, longitude, []string{"weatherAlerts"})
 }
+
+func (c *Client) WeatherChanges(ctx context.Context, latitude float64, longitude float64) (Weather, error) {
+	return c.Weather(ctx, latitude, longitude, []string{"weatherChanges"})
+}
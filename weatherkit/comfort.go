@@ -0,0 +1,88 @@
+package weatherkit
+
+import (
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/indices"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+)
+
+// ComfortIndices are derived comfort and safety indices for a point in time.
+type ComfortIndices struct {
+	HeatIndex          *float32 `json:"heatIndex,omitempty"`
+	WindChill          *float32 `json:"windChill,omitempty"`
+	Humidex            *float32 `json:"humidex,omitempty"`
+	TemperatureWetBulb *float32 `json:"temperatureWetBulb,omitempty"`
+	Wbgt               *float32 `json:"wbgt,omitempty"`
+	BeaufortScale      *int     `json:"beaufortScale,omitempty"`
+}
+
+// newComfortIndices computes the indices from metric values. Indices whose
+// inputs are missing are left nil.
+func newComfortIndices(temperature, humidity, dewPoint, windSpeed *float32) ComfortIndices {
+	var c ComfortIndices
+	value := func(v float64) *float32 {
+		f := float32(v)
+		return &f
+	}
+	if temperature != nil && humidity != nil {
+		c.HeatIndex = value(indices.HeatIndex(float64(*temperature), float64(*humidity)))
+		c.TemperatureWetBulb = value(indices.WetBulbTemperature(float64(*temperature), float64(*humidity)))
+		c.Wbgt = value(indices.WBGT(float64(*temperature), float64(*humidity)))
+	}
+	if temperature != nil && windSpeed != nil {
+		c.WindChill = value(indices.WindChill(float64(*temperature), float64(*windSpeed)))
+	}
+	if temperature != nil && dewPoint != nil {
+		c.Humidex = value(indices.Humidex(float64(*temperature), float64(*dewPoint)))
+	}
+	if windSpeed != nil {
+		beaufort := indices.Beaufort(float64(*windSpeed))
+		c.BeaufortScale = &beaufort
+	}
+	return c
+}
+
+// comfortIndices converts the temperature-like indices. Humidex is a
+// dimensionless number and is never converted.
+func (u unitConverter) comfortIndices(c ComfortIndices) ComfortIndices {
+	c.HeatIndex = u.temperature(c.HeatIndex)
+	c.WindChill = u.temperature(c.WindChill)
+	c.TemperatureWetBulb = u.temperature(c.TemperatureWetBulb)
+	c.Wbgt = u.temperature(c.Wbgt)
+	return c
+}
+
+func comfortIndexColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "heat_index",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The NWS heat index, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "wind_chill",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The wind chill temperature, or the air temperature above 10°C or in light wind, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "humidex",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The Environment Canada humidex, a dimensionless number comparable to degrees Celsius.",
+		},
+		{
+			Name:        "temperature_wet_bulb",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The wet-bulb temperature at sea-level pressure, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "wbgt",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "An estimate of the wet-bulb globe temperature assuming moderate sun and light wind, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "beaufort_scale",
+			Type:        proto.ColumnType_INT,
+			Description: "The Beaufort wind force, from 0 (calm) to 12 (hurricane force).",
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package weatherkit
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+)
+
+// ConditionCode describes a WeatherKit condition code.
+type ConditionCode struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Severity    int    `json:"severity"`
+	DayIcon     string `json:"dayIcon"`
+	NightIcon   string `json:"nightIcon"`
+	Emoji       string `json:"emoji"`
+}
+
+// Condition categories, roughly ordered from benign to dangerous.
+const (
+	conditionCategoryClear      = "clear"
+	conditionCategoryCloud      = "cloud"
+	conditionCategoryVisibility = "visibility"
+	conditionCategoryWind       = "wind"
+	conditionCategoryRain       = "rain"
+	conditionCategorySnow       = "snow"
+	conditionCategoryStorm      = "storm"
+	conditionCategoryHazard     = "hazard"
+)
+
+// conditionCodes lists every known WeatherKit condition code. Severity ranks
+// conditions from 0 (benign) to 5 (life threatening). Icons are SF Symbols names.
+var conditionCodes = []ConditionCode{
+	{"Clear", "Clear", conditionCategoryClear, 0, "sun.max", "moon.stars", "☀️"},
+	{"MostlyClear", "Mostly clear", conditionCategoryClear, 0, "sun.max", "moon.stars", "🌤️"},
+	{"PartlyCloudy", "Partly cloudy", conditionCategoryCloud, 0, "cloud.sun", "cloud.moon", "⛅"},
+	{"MostlyCloudy", "Mostly cloudy", conditionCategoryCloud, 0, "cloud.sun", "cloud.moon", "🌥️"},
+	{"Cloudy", "Cloudy", conditionCategoryCloud, 0, "cloud", "cloud", "☁️"},
+	{"Foggy", "Fog", conditionCategoryVisibility, 1, "cloud.fog", "cloud.fog", "🌫️"},
+	{"Haze", "Haze", conditionCategoryVisibility, 1, "sun.haze", "moon.haze", "🌫️"},
+	{"Smoky", "Smoke", conditionCategoryVisibility, 2, "smoke", "smoke", "🌫️"},
+	{"BlowingDust", "Blowing dust or sandstorm", conditionCategoryVisibility, 2, "sun.dust", "moon.dust", "🌪️"},
+	{"Breezy", "Breezy, light wind", conditionCategoryWind, 0, "wind", "wind", "🍃"},
+	{"Windy", "Windy", conditionCategoryWind, 1, "wind", "wind", "💨"},
+	{"Drizzle", "Drizzle or light rain", conditionCategoryRain, 1, "cloud.drizzle", "cloud.drizzle", "🌦️"},
+	{"Rain", "Rain", conditionCategoryRain, 1, "cloud.rain", "cloud.rain", "🌧️"},
+	{"SunShowers", "Rain with visible sun", conditionCategoryRain, 1, "cloud.sun.rain", "cloud.moon.rain", "🌦️"},
+	{"ScatteredShowers", "Scattered showers", conditionCategoryRain, 1, "cloud.sun.rain", "cloud.moon.rain", "🌦️"},
+	{"Showers", "Showers", conditionCategoryRain, 1, "cloud.rain", "cloud.rain", "🌧️"},
+	{"HeavyRain", "Heavy rain", conditionCategoryRain, 2, "cloud.heavyrain", "cloud.heavyrain", "🌧️"},
+	{"Flurries", "Flurries or light snow", conditionCategorySnow, 1, "cloud.snow", "cloud.snow", "🌨️"},
+	{"SunFlurries", "Snow flurries with visible sun", conditionCategorySnow, 1, "sun.snow", "cloud.snow", "🌨️"},
+	{"Snow", "Snow", conditionCategorySnow, 2, "cloud.snow", "cloud.snow", "❄️"},
+	{"ScatteredSnowShowers", "Scattered snow showers", conditionCategorySnow, 2, "cloud.snow", "cloud.snow", "🌨️"},
+	{"SnowShowers", "Snow showers", conditionCategorySnow, 2, "cloud.snow", "cloud.snow", "🌨️"},
+	{"MixedRainAndSnow", "Mixed rain and snow", conditionCategorySnow, 2, "cloud.sleet", "cloud.sleet", "🌨️"},
+	{"MixedRainAndSleet", "Mixed rain and sleet", conditionCategorySnow, 2, "cloud.sleet", "cloud.sleet", "🌨️"},
+	{"MixedSnowAndSleet", "Mixed snow and sleet", conditionCategorySnow, 2, "cloud.sleet", "cloud.sleet", "🌨️"},
+	{"Sleet", "Sleet", conditionCategorySnow, 2, "cloud.sleet", "cloud.sleet", "🌨️"},
+	{"WintryMix", "Wintry mix", conditionCategorySnow, 2, "cloud.sleet", "cloud.sleet", "🌨️"},
+	{"HeavySnow", "Heavy snow", conditionCategorySnow, 3, "cloud.snow", "cloud.snow", "❄️"},
+	{"BlowingSnow", "Blowing snow", conditionCategorySnow, 3, "wind.snow", "wind.snow", "🌬️"},
+	{"FreezingDrizzle", "Freezing drizzle", conditionCategorySnow, 3, "cloud.sleet", "cloud.sleet", "🧊"},
+	{"FreezingRain", "Freezing rain", conditionCategorySnow, 3, "cloud.sleet", "cloud.sleet", "🧊"},
+	{"Blizzard", "Blizzard", conditionCategorySnow, 4, "wind.snow", "wind.snow", "🌨️"},
+	{"IsolatedThunderstorms", "Isolated thunderstorms", conditionCategoryStorm, 2, "cloud.sun.bolt", "cloud.moon.bolt", "⛈️"},
+	{"ScatteredThunderstorms", "Scattered thunderstorms", conditionCategoryStorm, 2, "cloud.sun.bolt", "cloud.moon.bolt", "⛈️"},
+	{"Thunderstorms", "Thunderstorms", conditionCategoryStorm, 3, "cloud.bolt.rain", "cloud.bolt.rain", "⛈️"},
+	{"StrongStorms", "Strong storms", conditionCategoryStorm, 4, "cloud.bolt.rain", "cloud.bolt.rain", "⛈️"},
+	{"SevereThunderstorm", "Severe thunderstorm", conditionCategoryStorm, 4, "cloud.bolt.rain", "cloud.bolt.rain", "⛈️"},
+	{"Hail", "Hail", conditionCategoryStorm, 3, "cloud.hail", "cloud.hail", "🧊"},
+	{"Frigid", "Frigid conditions, low temperatures, or ice crystals", conditionCategoryHazard, 3, "thermometer.snowflake", "thermometer.snowflake", "🥶"},
+	{"Hot", "High temperatures", conditionCategoryHazard, 3, "thermometer.sun", "thermometer.sun", "🥵"},
+	{"TropicalStorm", "Tropical storm", conditionCategoryHazard, 4, "tropicalstorm", "tropicalstorm", "🌀"},
+	{"Hurricane", "Hurricane", conditionCategoryHazard, 5, "hurricane", "hurricane", "🌀"},
+	{"Tornado", "Tornado", conditionCategoryHazard, 5, "tornado", "tornado", "🌪️"},
+}
+
+// conditionCodeByCode indexes conditionCodes by code.
+var conditionCodeByCode = func() map[string]ConditionCode {
+	m := make(map[string]ConditionCode, len(conditionCodes))
+	for _, c := range conditionCodes {
+		m[c.Code] = c
+	}
+	return m
+}()
+
+// conditionDescription is a transform that returns the human description of
+// a condition code, or nil for unknown codes.
+func conditionDescription(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	var code string
+	switch v := d.Value.(type) {
+	case string:
+		code = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		code = *v
+	}
+	if c, ok := conditionCodeByCode[code]; ok {
+		return c.Description, nil
+	}
+	return nil, nil
+}
+
+// conditionDescriptionColumn returns a column describing the condition_code column of a row.
+func conditionDescriptionColumn() *plugin.Column {
+	return &plugin.Column{
+		Name:        "condition_description",
+		Type:        proto.ColumnType_STRING,
+		Description: "A human-readable description of the condition code.",
+		Transform:   transform.FromField("ConditionCode").Transform(conditionDescription),
+	}
+}
@@ -6,11 +6,18 @@ import (
 )
 
 type weatherKitConfig struct {
-	KeyId          *string `cty:"key_id"`
-	ServiceId      *string `cty:"service_id"`
-	TeamId         *string `cty:"team_id"`
-	PrivateKeyPath *string `cty:"private_key_path"`
-	Token          *string `cty:"token"`
+	KeyId               *string  `cty:"key_id"`
+	ServiceId           *string  `cty:"service_id"`
+	TeamId              *string  `cty:"team_id"`
+	PrivateKeyPath      *string  `cty:"private_key_path"`
+	Token               *string  `cty:"token"`
+	Units               *string  `cty:"units"`
+	Locations           []string `cty:"locations"`
+	LocationsFile       *string  `cty:"locations_file"`
+	MaxConcurrency      *int     `cty:"max_concurrency"`
+	CoordinatePrecision *int     `cty:"coordinate_precision"`
+	SnapshotDir         *string  `cty:"snapshot_dir"`
+	RuleSets            []string `cty:"rule_sets"`
 }
 
 var ConfigSchema = map[string]*schema.Attribute{
@@ -29,6 +36,29 @@ var ConfigSchema = map[string]*schema.Attribute{
 	"token": {
 		Type: schema.TypeString,
 	},
+	"units": {
+		Type: schema.TypeString,
+	},
+	"locations": {
+		Type: schema.TypeList,
+		Elem: &schema.Attribute{Type: schema.TypeString},
+	},
+	"locations_file": {
+		Type: schema.TypeString,
+	},
+	"max_concurrency": {
+		Type: schema.TypeInt,
+	},
+	"coordinate_precision": {
+		Type: schema.TypeInt,
+	},
+	"snapshot_dir": {
+		Type: schema.TypeString,
+	},
+	"rule_sets": {
+		Type: schema.TypeList,
+		Elem: &schema.Attribute{Type: schema.TypeString},
+	},
 }
 
 func ConfigInstance() interface{} {
@@ -0,0 +1,176 @@
+// Package energy estimates the output of solar panels and wind turbines from
+// weather forecasts.
+//
+// Angles are in degrees, irradiance is in watts per square meter, wind speeds
+// are in meters per second, power is in kilowatts and temperatures are in
+// degrees Celsius. Azimuths are compass bearings, with 180 facing south.
+package energy
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+const rad = math.Pi / 180
+
+// solarConstant is the mean extraterrestrial irradiance.
+const solarConstant = 1361
+
+// Irradiance is the solar irradiance on a horizontal surface (GHI), its
+// direct normal (DNI) and diffuse horizontal (DHI) components, and the
+// irradiance on the plane of a panel (POA).
+type Irradiance struct {
+	GHI float64
+	DNI float64
+	DHI float64
+	POA float64
+}
+
+// airMass returns the relative optical air mass at a solar zenith angle,
+// using the Kasten and Young (1989) formula.
+func airMass(zenith float64) float64 {
+	return 1 / (math.Cos(zenith*rad) + 0.50572*math.Pow(96.07995-zenith, -1.6364))
+}
+
+// ClearSky returns the clear sky irradiance for a sun altitude, with the
+// direct normal irradiance from the Meinel model and the diffuse irradiance
+// taken as a tenth of it.
+func ClearSky(altitude float64) Irradiance {
+	if altitude <= 0 {
+		return Irradiance{}
+	}
+	zenith := 90 - altitude
+	dni := solarConstant * math.Pow(0.7, math.Pow(airMass(zenith), 0.678))
+	dhi := 0.1 * dni
+	return Irradiance{GHI: dni*math.Cos(zenith*rad) + dhi, DNI: dni, DHI: dhi}
+}
+
+// Cloudy attenuates clear sky irradiance by a cloud cover from 0 to 1. The
+// global irradiance follows Kasten and Czeplak (1980), the direct irradiance
+// falls in proportion to the cloud cover, and the rest of the global
+// irradiance is diffuse.
+func Cloudy(clear Irradiance, altitude, cloudCover float64) Irradiance {
+	if altitude <= 0 {
+		return Irradiance{}
+	}
+	cloudCover = math.Max(0, math.Min(1, cloudCover))
+	ghi := clear.GHI * (1 - 0.75*math.Pow(cloudCover, 3.4))
+	dni := clear.DNI * (1 - cloudCover)
+	dhi := math.Max(0, ghi-dni*math.Sin(altitude*rad))
+	return Irradiance{GHI: ghi, DNI: dni, DHI: dhi}
+}
+
+// groundAlbedo is the fraction of global irradiance reflected by the ground.
+const groundAlbedo = 0.2
+
+// PlaneOfArray returns the irradiance with the irradiance on a panel of the
+// given tilt and azimuth set, for the sun at the given altitude and azimuth,
+// with an isotropic sky.
+func PlaneOfArray(i Irradiance, altitude, azimuth, tilt, panelAzimuth float64) Irradiance {
+	if altitude <= 0 {
+		return i
+	}
+	zenith := 90 - altitude
+	cosIncidence := math.Cos(zenith*rad)*math.Cos(tilt*rad) + math.Sin(zenith*rad)*math.Sin(tilt*rad)*math.Cos((azimuth-panelAzimuth)*rad)
+	beam := i.DNI * math.Max(0, cosIncidence)
+	sky := i.DHI * (1 + math.Cos(tilt*rad)) / 2
+	ground := i.GHI * groundAlbedo * (1 - math.Cos(tilt*rad)) / 2
+	i.POA = beam + sky + ground
+	return i
+}
+
+const (
+	// systemEfficiency covers inverter, wiring, soiling and mismatch losses.
+	systemEfficiency = 0.86
+	// temperatureCoefficient is the change in panel output per degree of
+	// cell temperature above 25°C, typical of crystalline silicon.
+	temperatureCoefficient = -0.004
+	// noct is the nominal operating cell temperature.
+	noct = 45
+)
+
+// PVPower returns the output of panels with a peak capacity, in kilowatts,
+// for the irradiance on their plane and the air temperature.
+func PVPower(capacity, poa, temperature float64) float64 {
+	if poa <= 0 {
+		return 0
+	}
+	cell := temperature + poa*(noct-20)/800
+	derate := math.Max(0, 1+temperatureCoefficient*(cell-25))
+	return capacity * poa / 1000 * derate * systemEfficiency
+}
+
+// HubWindSpeed extrapolates a wind speed measured at a reference height to
+// the hub height with the power law and a wind shear exponent, typically
+// 1/7 over open land.
+func HubWindSpeed(speed, referenceHeight, hubHeight, shear float64) float64 {
+	return speed * math.Pow(hubHeight/referenceHeight, shear)
+}
+
+// PowerCurve gives the output of a wind turbine, in kilowatts, at wind speeds.
+// Points are sorted by speed. Output is interpolated linearly between points
+// and is zero below the first point and above the last, the cut-in and
+// cut-out speeds.
+type PowerCurve [][2]float64
+
+// NewPowerCurve returns a power curve from speed and output points.
+func NewPowerCurve(points [][2]float64) (PowerCurve, error) {
+	if len(points) < 2 {
+		return nil, errors.New("a power curve needs at least two points")
+	}
+	curve := append(PowerCurve(nil), points...)
+	// A stable sort keeps the order of points at the same speed, such as a
+	// step down to a reduced output at high wind.
+	sort.SliceStable(curve, func(i, j int) bool { return curve[i][0] < curve[j][0] })
+	for _, p := range curve {
+		if p[0] < 0 || p[1] < 0 {
+			return nil, errors.New("power curve speeds and outputs must not be negative")
+		}
+	}
+	return curve, nil
+}
+
+// Generic turbine speeds, in meters per second.
+const (
+	cutIn   = 3
+	rated   = 12
+	cutOut  = 25
+	samples = 10
+)
+
+// GenericPowerCurve returns the curve of a generic turbine with a rated
+// capacity in kilowatts: output rises with the cube of the wind speed from
+// 3 m/s to its capacity at 12 m/s, and the turbine stops above 25 m/s.
+func GenericPowerCurve(capacity float64) PowerCurve {
+	curve := PowerCurve{{cutIn, 0}}
+	for i := 1; i <= samples; i++ {
+		v := cutIn + float64(i)*(rated-cutIn)/samples
+		fraction := (math.Pow(v, 3) - cutIn*cutIn*cutIn) / (rated*rated*rated - cutIn*cutIn*cutIn)
+		curve = append(curve, [2]float64{v, capacity * fraction})
+	}
+	return append(curve, [2]float64{cutOut, capacity})
+}
+
+// Power returns the output of the turbine at a wind speed. An unknown (NaN)
+// speed gives no output.
+func (c PowerCurve) Power(speed float64) float64 {
+	if len(c) == 0 || !(speed >= c[0][0] && speed <= c[len(c)-1][0]) {
+		return 0
+	}
+	i := sort.Search(len(c), func(i int) bool { return c[i][0] >= speed })
+	if c[i][0] == speed || i == 0 {
+		return c[i][1]
+	}
+	a, b := c[i-1], c[i]
+	return a[1] + (b[1]-a[1])*(speed-a[0])/(b[0]-a[0])
+}
+
+// Capacity returns the highest output of the curve.
+func (c PowerCurve) Capacity() float64 {
+	var max float64
+	for _, p := range c {
+		max = math.Max(max, p[1])
+	}
+	return max
+}
@@ -0,0 +1,137 @@
+package energy
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func TestNewPowerCurve(t *testing.T) {
+	curve, err := NewPowerCurve([][2]float64{{12, 2000}, {3, 0}, {25, 2000}, {8, 800}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(curve); i++ {
+		if curve[i][0] < curve[i-1][0] {
+			t.Fatalf("NewPowerCurve returned unsorted points %v", curve)
+		}
+	}
+
+	for _, points := range [][][2]float64{
+		nil,
+		{{3, 0}},
+		{{3, 0}, {-1, 100}},
+		{{3, 0}, {12, -5}},
+	} {
+		if _, err := NewPowerCurve(points); err == nil {
+			t.Errorf("NewPowerCurve(%v) succeeded, want an error", points)
+		}
+	}
+}
+
+func TestPowerCurvePower(t *testing.T) {
+	curve, err := NewPowerCurve([][2]float64{{3, 0}, {5, 200}, {10, 1500}, {12, 2000}, {20, 2000}, {20, 1000}, {25, 1000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		speed, want float64
+	}{
+		// Below cut-in and above cut-out there is no output.
+		{0, 0},
+		{2.99, 0},
+		{25.01, 0},
+		{40, 0},
+		{math.NaN(), 0},
+		// Points are returned exactly.
+		{3, 0},
+		{5, 200},
+		{12, 2000},
+		{25, 1000},
+		// Output is interpolated linearly between points.
+		{4, 100},
+		{7.5, 850},
+		{11, 1750},
+		{16, 2000},
+		// A step keeps the first output at its speed and the second above.
+		{20, 2000},
+		{22.5, 1000},
+	}
+	for _, tt := range tests {
+		if got := curve.Power(tt.speed); math.Abs(got-tt.want) > tolerance {
+			t.Errorf("Power(%g) = %g, want %g", tt.speed, got, tt.want)
+		}
+	}
+	if got := curve.Capacity(); got != 2000 {
+		t.Errorf("Capacity() = %g, want 2000", got)
+	}
+
+	var empty PowerCurve
+	if got := empty.Power(10); got != 0 {
+		t.Errorf("empty Power(10) = %g, want 0", got)
+	}
+	if got := empty.Capacity(); got != 0 {
+		t.Errorf("empty Capacity() = %g, want 0", got)
+	}
+}
+
+func TestGenericPowerCurve(t *testing.T) {
+	curve := GenericPowerCurve(3000)
+	if got := curve.Capacity(); got != 3000 {
+		t.Errorf("Capacity() = %g, want 3000", got)
+	}
+	tests := []struct {
+		speed, want float64
+	}{
+		{2.9, 0},
+		{3, 0},
+		{12, 3000},
+		{18, 3000},
+		{25, 3000},
+		{25.1, 0},
+	}
+	for _, tt := range tests {
+		if got := curve.Power(tt.speed); math.Abs(got-tt.want) > tolerance {
+			t.Errorf("Power(%g) = %g, want %g", tt.speed, got, tt.want)
+		}
+	}
+	// Output rises with the cube of the speed at the sampled points, and
+	// never falls between cut-in and the rated speed.
+	v := 3 + 9*0.5
+	want := 3000 * (v*v*v - 27) / (12*12*12 - 27)
+	if got := curve.Power(v); math.Abs(got-want) > tolerance {
+		t.Errorf("Power(%g) = %g, want %g", v, got, want)
+	}
+	for speed, last := 3.0, -1.0; speed <= 12; speed += 0.25 {
+		got := curve.Power(speed)
+		if got < last {
+			t.Fatalf("Power(%g) = %g, below Power(%g) = %g", speed, got, speed-0.25, last)
+		}
+		last = got
+	}
+}
+
+func TestPVPower(t *testing.T) {
+	if got := PVPower(10, 0, 20); got != 0 {
+		t.Errorf("PVPower at night = %g, want 0", got)
+	}
+	// At 800 W/m² and 20°C the cell is at the nominal operating temperature.
+	want := 10 * 0.8 * (1 - 0.004*(45-25)) * systemEfficiency
+	if got := PVPower(10, 800, 20); math.Abs(got-want) > tolerance {
+		t.Errorf("PVPower(10, 800, 20) = %g, want %g", got, want)
+	}
+	if hot, cool := PVPower(10, 800, 35), PVPower(10, 800, 5); hot >= cool {
+		t.Errorf("PVPower at 35°C = %g, want below %g at 5°C", hot, cool)
+	}
+}
+
+func TestHubWindSpeed(t *testing.T) {
+	if got := HubWindSpeed(5, 10, 10, 1.0/7); math.Abs(got-5) > tolerance {
+		t.Errorf("HubWindSpeed at the reference height = %g, want 5", got)
+	}
+	want := 5 * math.Pow(8, 1.0/7)
+	if got := HubWindSpeed(5, 10, 80, 1.0/7); math.Abs(got-want) > tolerance {
+		t.Errorf("HubWindSpeed(5, 10, 80) = %g, want %g", got, want)
+	}
+}
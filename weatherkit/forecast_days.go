@@ -0,0 +1,68 @@
+package weatherkit
+
+import (
+	"context"
+	"time"
+)
+
+// forecastDay is a day of the daily forecast with the hours of the hourly
+// forecast that start within it.
+type forecastDay struct {
+	Start time.Time
+	End   time.Time
+	Day   DayWeatherConditions
+	Hours []HourWeatherConditions
+}
+
+// complete reports whether the hourly forecast covers the whole day.
+func (d forecastDay) complete() bool {
+	return len(d.Hours) > 0 && len(d.Hours) >= int(d.End.Sub(d.Start).Hours())
+}
+
+// fetchForecastDays requests the daily forecast for a location, then the
+// hourly forecast over the same days, and returns the days in the given
+// units with their hours. Hours past the end of the hourly forecast are
+// missing, so the last days can have few or no hours.
+func fetchForecastDays(ctx context.Context, service *Client, location queryLocation, units unitSystem) ([]forecastDay, WeatherMetadata, error) {
+	daily, err := service.DailyForecast(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, WeatherMetadata{}, err
+	}
+	metadata := daily.DailyForecast.Metadata
+	converter, err := newUnitConverter(metadata, units)
+	if err != nil {
+		return nil, metadata, err
+	}
+	var days []forecastDay
+	for _, day := range daily.DailyForecast.Days {
+		start, end := parseTime(day.ForecastStart), parseTime(day.ForecastEnd)
+		if start == nil || end == nil {
+			continue
+		}
+		days = append(days, forecastDay{Start: *start, End: *end, Day: converter.dayWeatherConditions(day)})
+	}
+	if len(days) == 0 {
+		return nil, metadata, nil
+	}
+	hourly, err := service.HourlyForecastRange(ctx, location.Latitude, location.Longitude, days[0].Start, days[len(days)-1].End)
+	if err != nil {
+		return nil, metadata, err
+	}
+	converter, err = newUnitConverter(hourly.HourlyForecast.Metadata, units)
+	if err != nil {
+		return nil, metadata, err
+	}
+	for _, hour := range hourly.HourlyForecast.Hours {
+		start := parseTime(hour.ForecastStart)
+		if start == nil {
+			continue
+		}
+		for i := range days {
+			if !start.Before(days[i].Start) && start.Before(days[i].End) {
+				days[i].Hours = append(days[i].Hours, converter.hourWeatherConditions(hour))
+				break
+			}
+		}
+	}
+	return days, metadata, nil
+}
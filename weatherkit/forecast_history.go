@@ -0,0 +1,67 @@
+package weatherkit
+
+import (
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/snapshot"
+	"sync"
+	"time"
+)
+
+// previousLookback bounds how far back the snapshot store is searched for
+// the previous read of a forecast.
+const previousLookback = 7 * 24 * time.Hour
+
+// forecastHistory keeps the latest reads of each dataset and location in
+// memory, for comparing forecasts when there is no snapshot store. Only the
+// two most recent reads are kept.
+type forecastHistory struct {
+	mu      sync.Mutex
+	records map[string][]snapshot.Record
+}
+
+var seenForecasts = &forecastHistory{records: map[string][]snapshot.Record{}}
+
+func historyKey(dataset string, latitude float64, longitude float64) string {
+	return fmt.Sprintf("%s/%.4f/%.4f", dataset, latitude, longitude)
+}
+
+// record adds a read to the history and returns the latest read before it,
+// or nil if none has been seen.
+func (h *forecastHistory) record(r snapshot.Record) *snapshot.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := historyKey(r.Dataset, r.Latitude, r.Longitude)
+	var previous *snapshot.Record
+	for _, seen := range h.records[key] {
+		if seen.ReadTime.Before(r.ReadTime) && (previous == nil || seen.ReadTime.After(previous.ReadTime)) {
+			seen := seen
+			previous = &seen
+		}
+	}
+	var kept []snapshot.Record
+	if previous != nil {
+		kept = append(kept, *previous)
+	}
+	h.records[key] = append(kept, r)
+	return previous
+}
+
+// previousSnapshot returns the latest stored read of the dataset at the
+// location before the read time, or nil if there is none within
+// previousLookback.
+func previousSnapshot(store *snapshot.Store, r snapshot.Record) (*snapshot.Record, error) {
+	filter := snapshot.Filter{
+		Datasets:  []string{r.Dataset},
+		Locations: [][2]float64{{r.Latitude, r.Longitude}},
+		From:      r.ReadTime.Add(-previousLookback),
+		To:        r.ReadTime,
+	}
+	var previous *snapshot.Record
+	err := store.Scan(filter, func(stored snapshot.Record) error {
+		if stored.ReadTime.Before(r.ReadTime) && (previous == nil || stored.ReadTime.After(previous.ReadTime)) {
+			previous = &stored
+		}
+		return nil
+	})
+	return previous, err
+}
@@ -0,0 +1,238 @@
+package weatherkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/snapshot"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"math"
+	"time"
+)
+
+const (
+	datasetCurrent = "currentWeather"
+	datasetDaily   = "forecastDaily"
+	datasetHourly  = "forecastHourly"
+	datasetAlerts  = "weatherAlerts"
+)
+
+// recordSnapshots stores the current weather, the daily and hourly forecasts
+// and the weather alerts of a response when the snapshot_dir connection
+// option is set.
+// Failures are logged rather than failing the query.
+func (c *Client) recordSnapshots(latitude float64, longitude float64, weather Weather) {
+	if c.config.SnapshotDir == nil || *c.config.SnapshotDir == "" {
+		return
+	}
+	if weather.CurrentWeather.AsOf != nil {
+		c.recordSnapshot(datasetCurrent, latitude, longitude, weather.CurrentWeather.Metadata, weather.CurrentWeather)
+	}
+	if len(weather.DailyForecast.Days) > 0 {
+		c.recordSnapshot(datasetDaily, latitude, longitude, weather.DailyForecast.Metadata, weather.DailyForecast)
+	}
+	if len(weather.HourlyForecast.Hours) > 0 {
+		c.recordSnapshot(datasetHourly, latitude, longitude, weather.HourlyForecast.Metadata, weather.HourlyForecast)
+	}
+	// Alerts are recorded even when there are none, so that their removal
+	// can be seen.
+	if weather.WeatherAlerts.Metadata.ReadTime != nil {
+		c.recordSnapshot(datasetAlerts, latitude, longitude, weather.WeatherAlerts.Metadata, weather.WeatherAlerts)
+	}
+}
+
+func (c *Client) recordSnapshot(dataset string, latitude float64, longitude float64, metadata WeatherMetadata, data interface{}) {
+	store, err := openSnapshotStore(c.config)
+	if err != nil {
+		c.logger.Error("recordSnapshot", "dataset", dataset, "error", err)
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		c.logger.Error("recordSnapshot", "dataset", dataset, "error", err)
+		return
+	}
+	readTime := time.Now().UTC()
+	if t := parseTime(metadata.ReadTime); t != nil {
+		readTime = *t
+	}
+	err = store.Append(snapshot.Record{
+		Dataset:   dataset,
+		Latitude:  latitude,
+		Longitude: longitude,
+		ReadTime:  readTime,
+		Data:      raw,
+	})
+	if err != nil {
+		c.logger.Error("recordSnapshot", "dataset", dataset, "error", err)
+	}
+}
+
+// openSnapshotStore opens the store in the snapshot_dir connection option.
+func openSnapshotStore(config *weatherKitConfig) (*snapshot.Store, error) {
+	if config.SnapshotDir == nil || *config.SnapshotDir == "" {
+		return nil, errors.New("snapshot_dir must be set in the connection config to record forecasts")
+	}
+	dir, err := expandHome(*config.SnapshotDir)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Open(dir)
+}
+
+// forecastSnapshot is a day or hour of a stored forecast.
+type forecastSnapshot struct {
+	Dataset             string          `json:"dataset"`
+	ReadTime            time.Time       `json:"readTime"`
+	ForecastStart       *string         `json:"forecastStart,omitempty"`
+	ForecastEnd         *string         `json:"forecastEnd,omitempty"`
+	LeadTimeHours       *float64        `json:"leadTimeHours,omitempty"`
+	LeadDays            *int            `json:"leadDays,omitempty"`
+	ConditionCode       *string         `json:"conditionCode,omitempty"`
+	Temperature         *float32        `json:"temperature,omitempty"`
+	TemperatureMax      *float32        `json:"temperatureMax,omitempty"`
+	TemperatureMin      *float32        `json:"temperatureMin,omitempty"`
+	Humidity            *float32        `json:"humidity,omitempty"`
+	PrecipitationChance *float32        `json:"precipitationChance,omitempty"`
+	PrecipitationAmount *float32        `json:"precipitationAmount,omitempty"`
+	WindSpeed           *float32        `json:"windSpeed,omitempty"`
+	WindGust            *float32        `json:"windGust,omitempty"`
+	Forecast            interface{}     `json:"forecast,omitempty"`
+	Units               unitSystem      `json:"units"`
+	Metadata            WeatherMetadata `json:"metadata,omitempty"`
+}
+
+// leadTime returns the hours from the read time to the start of a forecast
+// period, and the number of calendar days between the day the forecast was
+// read and the forecast day. Days are taken in local solar time, estimated
+// from the longitude, since a snapshot does not record the time zone. Daily
+// periods are dated by their middle, as they start at local midnight.
+func leadTime(readTime time.Time, forecastStart *string, longitude float64, daily bool) (*float64, *int) {
+	start := parseTime(forecastStart)
+	if start == nil {
+		return nil, nil
+	}
+	hours := start.Sub(readTime).Hours()
+	offset := time.Duration(longitude / 15 * float64(time.Hour))
+	day := start.Add(offset)
+	if daily {
+		day = day.Add(12 * time.Hour)
+	}
+	readDay := readTime.Add(offset).UTC().Truncate(24 * time.Hour)
+	days := int(math.Round(day.UTC().Truncate(24*time.Hour).Sub(readDay).Hours() / 24))
+	return &hours, &days
+}
+
+// decodeSnapshot returns the days or hours of a stored forecast in the given
+// units.
+func decodeSnapshot(r snapshot.Record, units unitSystem) ([]forecastSnapshot, error) {
+	var snapshots []forecastSnapshot
+	switch r.Dataset {
+	case datasetDaily:
+		var data DailyForecastData
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			return nil, err
+		}
+		converter, err := newUnitConverter(data.Metadata, units)
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range data.Days {
+			day = converter.dayWeatherConditions(day)
+			s := forecastSnapshot{
+				Dataset:             r.Dataset,
+				ReadTime:            r.ReadTime,
+				ForecastStart:       day.ForecastStart,
+				ForecastEnd:         day.ForecastEnd,
+				ConditionCode:       day.ConditionCode,
+				TemperatureMax:      day.TemperatureMax,
+				TemperatureMin:      day.TemperatureMin,
+				PrecipitationChance: day.PrecipitationChance,
+				PrecipitationAmount: day.PrecipitationAmount,
+				Forecast:            day,
+				Units:               units,
+				Metadata:            data.Metadata,
+			}
+			s.LeadTimeHours, s.LeadDays = leadTime(r.ReadTime, day.ForecastStart, r.Longitude, true)
+			snapshots = append(snapshots, s)
+		}
+	case datasetHourly:
+		var data HourlyForecastData
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			return nil, err
+		}
+		converter, err := newUnitConverter(data.Metadata, units)
+		if err != nil {
+			return nil, err
+		}
+		for _, hour := range data.Hours {
+			hour = converter.hourWeatherConditions(hour)
+			var end *string
+			if start := parseTime(hour.ForecastStart); start != nil {
+				e := start.Add(time.Hour).Format(time.RFC3339)
+				end = &e
+			}
+			s := forecastSnapshot{
+				Dataset:             r.Dataset,
+				ReadTime:            r.ReadTime,
+				ForecastStart:       hour.ForecastStart,
+				ForecastEnd:         end,
+				ConditionCode:       hour.ConditionCode,
+				Temperature:         hour.Temperature,
+				Humidity:            hour.Humidity,
+				PrecipitationChance: hour.PrecipitationChance,
+				PrecipitationAmount: hour.PrecipitationAmount,
+				WindSpeed:           hour.WindSpeed,
+				WindGust:            hour.WindGust,
+				Forecast:            hour,
+				Units:               units,
+				Metadata:            data.Metadata,
+			}
+			s.LeadTimeHours, s.LeadDays = leadTime(r.ReadTime, hour.ForecastStart, r.Longitude, false)
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+// hasLocationQuals reports whether the query gives a location.
+func hasLocationQuals(d *plugin.QueryData) bool {
+	for _, c := range locationKeyColumns() {
+		if _, ok := d.KeyColumnQuals[c.Name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// scanSnapshots calls fn for each stored record of the datasets read within
+// the range, for the locations given by the quals or, if none are given, for
+// every location. The location passed to fn is the one given by the quals,
+// or the location of the record.
+func scanSnapshots(ctx context.Context, d *plugin.QueryData, datasets []string, from, to time.Time, fn func(location queryLocation, r snapshot.Record) error) error {
+	config := GetConfig(d.Connection)
+	store, err := openSnapshotStore(&config)
+	if err != nil {
+		return err
+	}
+	filter := snapshot.Filter{Datasets: datasets, From: from, To: to}
+	if !hasLocationQuals(d) {
+		return store.Scan(filter, func(r snapshot.Record) error {
+			return fn(queryLocation{Latitude: r.Latitude, Longitude: r.Longitude}, r)
+		})
+	}
+	locations, err := resolveLocations(ctx, d)
+	if err != nil {
+		return err
+	}
+	for _, location := range locations {
+		filter.Locations = [][2]float64{{location.Latitude, location.Longitude}}
+		err := store.Scan(filter, func(r snapshot.Record) error {
+			return fn(location, r)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
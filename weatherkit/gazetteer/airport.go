@@ -0,0 +1,83 @@
+package gazetteer
+
+//go:generate go run generate_airports.go -out airports.tsv.gz
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// airportsData is the embedded airport dataset. The file currently committed
+// is a hand-picked seed list of 185 airports in the generator's format rather
+// than the generator's output. It must be replaced by running `go generate`
+// before release.
+//
+//go:embed airports.tsv.gz
+var airportsData []byte
+
+// Airport is an airport with its IATA and ICAO codes.
+type Airport struct {
+	IataCode     string  `json:"iataCode"`
+	IcaoCode     string  `json:"icaoCode"`
+	Name         string  `json:"name"`
+	Municipality string  `json:"municipality"`
+	CountryCode  string  `json:"countryCode"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+}
+
+// DisplayName returns the codes and name of the airport, e.g.
+// "DTW/KDTW Detroit Metropolitan Wayne County Airport".
+func (a Airport) DisplayName() string {
+	codes := a.IcaoCode
+	if a.IataCode != "" {
+		codes = a.IataCode + "/" + a.IcaoCode
+	}
+	return codes + " " + a.Name
+}
+
+var (
+	airportsOnce sync.Once
+	airports     []Airport
+	airportsErr  error
+)
+
+// Airports returns every airport in the embedded dataset.
+func Airports() ([]Airport, error) {
+	airportsOnce.Do(func() {
+		airportsErr = readTSV("airports", airportsData, 7, func(fields []string) error {
+			latitude, longitude, err := parseCoordinates(fields[5], fields[6])
+			if err != nil {
+				return err
+			}
+			airports = append(airports, Airport{
+				IataCode:     fields[0],
+				IcaoCode:     fields[1],
+				Name:         fields[2],
+				Municipality: fields[3],
+				CountryCode:  fields[4],
+				Latitude:     latitude,
+				Longitude:    longitude,
+			})
+			return nil
+		})
+	})
+	return airports, airportsErr
+}
+
+// LookupAirport returns the airport with the given three letter IATA or four
+// letter ICAO code, ignoring case.
+func LookupAirport(code string) (Airport, bool, error) {
+	all, err := Airports()
+	if err != nil {
+		return Airport{}, false, err
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, a := range all {
+		if (len(code) == 3 && a.IataCode == code) || (len(code) == 4 && a.IcaoCode == code) {
+			return a, true, nil
+		}
+	}
+	return Airport{}, false, nil
+}
@@ -0,0 +1,310 @@
+// Package gazetteer resolves place names, postal codes and airport codes to
+// coordinates without network access, using datasets embedded in the binary.
+//
+// The places are meant to be derived from the GeoNames cities dump
+// (https://www.geonames.org, CC BY 4.0) and, like the other datasets, are
+// regenerated with `go generate`, which downloads the dump; the -url flag
+// also accepts a dump downloaded beforehand. Each line of
+// cities.tsv.gz holds the tab separated name, ASCII name, admin1 code,
+// country code, latitude, longitude, population and time zone of a place.
+// Postal codes also come from GeoNames and airports from OurAirports.
+//
+// The cities.tsv.gz currently committed is a hand-picked seed list of 325
+// places in the generator's format, not the generator's output, and includes
+// places below the 100,000 population threshold. It must be replaced by
+// running `go generate` before release.
+package gazetteer
+
+//go:generate go run generate.go -min-population 100000 -out cities.tsv.gz
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed cities.tsv.gz
+var citiesData []byte
+
+// Place is a populated place in the gazetteer.
+type Place struct {
+	Name        string  `json:"name"`
+	AsciiName   string  `json:"asciiName"`
+	Admin1Code  string  `json:"admin1Code"`
+	CountryCode string  `json:"countryCode"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Population  int64   `json:"population"`
+	Timezone    string  `json:"timezone"`
+}
+
+// DisplayName returns the name of the place qualified by its admin1 code,
+// where there is one, and its country code, e.g. "Ann Arbor, MI, US".
+func (p Place) DisplayName() string {
+	parts := []string{p.Name}
+	if p.Admin1Code != "" {
+		parts = append(parts, p.Admin1Code)
+	}
+	return strings.Join(append(parts, p.CountryCode), ", ")
+}
+
+var (
+	loadOnce sync.Once
+	places   []Place
+	loadErr  error
+)
+
+// Places returns every place in the gazetteer. The embedded list is
+// decompressed the first time it is needed.
+func Places() ([]Place, error) {
+	loadOnce.Do(func() {
+		places, loadErr = load(citiesData)
+	})
+	return places, loadErr
+}
+
+func load(data []byte) ([]Place, error) {
+	var result []Place
+	err := readTSV("cities", data, 8, func(fields []string) error {
+		latitude, longitude, err := parseCoordinates(fields[4], fields[5])
+		if err != nil {
+			return err
+		}
+		population, err := strconv.ParseInt(fields[6], 10, 64)
+		if err != nil {
+			return err
+		}
+		result = append(result, Place{
+			Name:        fields[0],
+			AsciiName:   fields[1],
+			Admin1Code:  fields[2],
+			CountryCode: fields[3],
+			Latitude:    latitude,
+			Longitude:   longitude,
+			Population:  population,
+			Timezone:    fields[7],
+		})
+		return nil
+	})
+	return result, err
+}
+
+// readTSV calls fn with the fields of each line of a gzipped, tab separated
+// dataset, checking each line has the expected number of fields.
+func readTSV(name string, data []byte, fields int, fn func([]string) error) error {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("gazetteer: %s: %w", name, err)
+	}
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		values := strings.Split(scanner.Text(), "\t")
+		if len(values) != fields {
+			return fmt.Errorf("gazetteer: %s line %d: expected %d fields, got %d", name, line, fields, len(values))
+		}
+		if err := fn(values); err != nil {
+			return fmt.Errorf("gazetteer: %s line %d: %w", name, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("gazetteer: %s: %w", name, err)
+	}
+	return nil
+}
+
+func parseCoordinates(latitude, longitude string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(latitude, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	lon, err := strconv.ParseFloat(longitude, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return lat, lon, nil
+}
+
+// Match is a place matching a search, with a score from 0 to 1.
+type Match struct {
+	Place
+	Score float64 `json:"score"`
+}
+
+// minScore is the lowest score returned by Search. It allows for a typo or
+// two in names of average length.
+const minScore = 0.75
+
+// Search returns the places best matching a query of the form
+// "name[, admin1][, country]", best first. The admin1 and country
+// qualifiers may be codes or names, e.g. "Ann Arbor, MI" or
+// "Paris, France". Names are matched ignoring case, accents and
+// punctuation, and tolerate small spelling mistakes. At most limit matches
+// are returned; a limit of 0 or less returns all matches.
+func Search(query string, limit int) ([]Match, error) {
+	all, err := Places()
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(query, ",")
+	name := normalize(parts[0])
+	if name == "" {
+		return nil, nil
+	}
+	var qualifiers []string
+	for _, q := range parts[1:] {
+		if q = normalize(q); q != "" {
+			qualifiers = append(qualifiers, q)
+		}
+	}
+
+	var matches []Match
+	for _, p := range all {
+		score := nameScore(name, normalize(p.AsciiName))
+		if p.Name != p.AsciiName {
+			if s := nameScore(name, normalize(p.Name)); s > score {
+				score = s
+			}
+		}
+		if score < minScore {
+			continue
+		}
+		for _, q := range qualifiers {
+			if !qualifierMatches(q, p) {
+				score *= 0.5
+			}
+		}
+		if score < minScore {
+			continue
+		}
+		matches = append(matches, Match{Place: p, Score: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Population > matches[j].Population
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// Lookup returns the best match for a query, or false if nothing matches.
+func Lookup(query string) (Match, bool, error) {
+	matches, err := Search(query, 1)
+	if err != nil || len(matches) == 0 {
+		return Match{}, false, err
+	}
+	return matches[0], true, nil
+}
+
+func nameScore(query, name string) float64 {
+	switch {
+	case query == name:
+		return 1
+	case strings.HasPrefix(name, query+" "):
+		return 0.9
+	}
+	longest := len(query)
+	if len(name) > longest {
+		longest = len(name)
+	}
+	return 1 - float64(levenshtein(query, name))/float64(longest)
+}
+
+func qualifierMatches(q string, p Place) bool {
+	if q == strings.ToLower(p.Admin1Code) || q == strings.ToLower(p.CountryCode) {
+		return true
+	}
+	if p.CountryCode == "US" && q == usStateNames[p.Admin1Code] {
+		return true
+	}
+	for _, name := range countryNames[p.CountryCode] {
+		if q == name {
+			return true
+		}
+	}
+	return false
+}
+
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// normalize lower cases a name, strips accents and replaces punctuation with
+// single spaces, so "Saint-Étienne" and "saint etienne" compare equal.
+func normalize(s string) string {
+	if stripped, _, err := transform.String(stripMarks, s); err == nil {
+		s = stripped
+	}
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// earthRadiusKm is the mean radius of the earth.
+const earthRadiusKm = 6371.0088
+
+// Distance returns the great circle distance in kilometers between two
+// coordinates.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// Nearest returns the place closest to a coordinate and its distance in
+// kilometers. ok is false only if the gazetteer is empty.
+func Nearest(latitude, longitude float64) (place Place, distance float64, ok bool, err error) {
+	all, err := Places()
+	if err != nil {
+		return Place{}, 0, false, err
+	}
+	for _, p := range all {
+		if d := Distance(latitude, longitude, p.Latitude, p.Longitude); !ok || d < distance {
+			place, distance, ok = p, d, true
+		}
+	}
+	return place, distance, ok, nil
+}
@@ -0,0 +1,157 @@
+package gazetteer
+
+import (
+	"math"
+	"testing"
+)
+
+// coordinateTolerance allows for the coordinates of a place being stored to
+// fewer decimal places than GeoNames gives, about 100 meters.
+const coordinateTolerance = 1e-3
+
+func TestLookup(t *testing.T) {
+	// Expected coordinates are those of the GeoNames cities dump.
+	tests := []struct {
+		query               string
+		name                string
+		admin1, country     string
+		latitude, longitude float64
+	}{
+		// Exact names.
+		{"Paris", "Paris", "", "FR", 48.85341, 2.3488},
+		{"Tokyo", "Tokyo", "", "JP", 35.6895, 139.69171},
+		// Accents and case are ignored.
+		{"zurich", "Zürich", "", "CH", 47.36667, 8.55},
+		{"SAO PAULO", "São Paulo", "", "BR", -23.5475, -46.63611},
+		{"Montréal", "Montréal", "", "CA", 45.50884, -73.58781},
+		// A one-letter typo still matches.
+		{"Zurch", "Zürich", "", "CH", 47.36667, 8.55},
+		{"Reykjavk", "Reykjavík", "", "IS", 64.13548, -21.89541},
+		// Without a qualifier the most populous place wins; admin1 and
+		// country qualifiers, as codes or names, pick another.
+		{"Springfield", "Springfield", "MO", "US", 37.21533, -93.29824},
+		{"Springfield, IL", "Springfield", "IL", "US", 39.80172, -89.64371},
+		{"Springfield, Massachusetts, United States", "Springfield", "MA", "US", 42.10148, -72.58981},
+		{"Portland, Oregon", "Portland", "OR", "US", 45.52345, -122.67621},
+		{"Ann Arbor, MI, US", "Ann Arbor", "MI", "US", 42.27756, -83.74088},
+		{"London, UK", "London", "", "GB", 51.50853, -0.12574},
+	}
+	for _, tt := range tests {
+		match, ok, err := Lookup(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("Lookup(%q) found nothing, want %s", tt.query, tt.name)
+			continue
+		}
+		if match.Name != tt.name || match.Admin1Code != tt.admin1 || match.CountryCode != tt.country {
+			t.Errorf("Lookup(%q) = %s, want %s, %s, %s", tt.query, match.DisplayName(), tt.name, tt.admin1, tt.country)
+			continue
+		}
+		if math.Abs(match.Latitude-tt.latitude) > coordinateTolerance || math.Abs(match.Longitude-tt.longitude) > coordinateTolerance {
+			t.Errorf("Lookup(%q) = %g, %g, want %g, %g", tt.query, match.Latitude, match.Longitude, tt.latitude, tt.longitude)
+		}
+	}
+}
+
+func TestLookupNoMatch(t *testing.T) {
+	for _, query := range []string{
+		"",
+		" , ",
+		"Xyzzyqwv",
+		// A qualifier that does not match rules out an otherwise exact name.
+		"Paris, Japan",
+		"Springfield, Texas",
+	} {
+		match, ok, err := Lookup(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Errorf("Lookup(%q) = %s, want no match", query, match.DisplayName())
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	matches, err := Search("Springfield", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) < 3 {
+		t.Fatalf("Search(Springfield) returned %d matches, want at least 3", len(matches))
+	}
+	// Exact matches score 1 and are ordered by population.
+	for i, m := range matches[:3] {
+		if m.Score != 1 {
+			t.Errorf("match %d %s scored %g, want 1", i, m.DisplayName(), m.Score)
+		}
+		if i > 0 && m.Population > matches[i-1].Population {
+			t.Errorf("match %d %s is more populous than the match before it", i, m.DisplayName())
+		}
+	}
+	if limited, err := Search("Springfield", 2); err != nil || len(limited) != 2 {
+		t.Errorf("Search(Springfield, 2) returned %d matches, %v, want 2", len(limited), err)
+	}
+	// A misspelt name scores below an exact one.
+	typo, err := Search("Pariss", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(typo) != 1 || typo[0].Name != "Paris" || typo[0].Score >= 1 || typo[0].Score < minScore {
+		t.Errorf("Search(Pariss) = %+v, want Paris with a score below 1", typo)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	tests := []struct {
+		latitude, longitude float64
+		name, country       string
+		maxDistance         float64
+	}{
+		{48.86, 2.35, "Paris", "FR", 2},
+		{42.28, -83.74, "Ann Arbor", "US", 1},
+		{-33.87, 151.21, "Sydney", "AU", 1},
+		{35.69, 139.69, "Tokyo", "JP", 1},
+	}
+	for _, tt := range tests {
+		place, distance, ok, err := Nearest(tt.latitude, tt.longitude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || place.Name != tt.name || place.CountryCode != tt.country || distance > tt.maxDistance {
+			t.Errorf("Nearest(%g, %g) = %s at %.1f km, want %s within %g km", tt.latitude, tt.longitude, place.DisplayName(), distance, tt.name, tt.maxDistance)
+		}
+	}
+	// The middle of the Pacific is far from any place.
+	if _, distance, ok, err := Nearest(0, -140); err != nil || !ok || distance < 1000 {
+		t.Errorf("Nearest(0, -140) = %.1f km, %v, %v, want over 1000 km", distance, ok, err)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{0, 0, 0, 0, 0},
+		// A degree of longitude at the equator and half the circumference.
+		{0, 0, 0, 1, 111.195},
+		{90, 0, -90, 0, 20015.114},
+		// Across the antimeridian.
+		{0, 179.5, 0, -179.5, 111.195},
+		// Paris to London and New York to London.
+		{48.85341, 2.3488, 51.50853, -0.12574, 343.8},
+		{40.71427, -74.00597, 51.50853, -0.12574, 5570.2},
+	}
+	for _, tt := range tests {
+		got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		if math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("Distance(%g, %g, %g, %g) = %.3f, want %.3f", tt.lat1, tt.lon1, tt.lat2, tt.lon2, got, tt.want)
+		}
+		if back := Distance(tt.lat2, tt.lon2, tt.lat1, tt.lon1); math.Abs(back-got) > 1e-9 {
+			t.Errorf("Distance is not symmetric: %g and %g", got, back)
+		}
+	}
+}
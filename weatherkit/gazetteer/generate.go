@@ -0,0 +1,108 @@
+//go:build ignore
+
+// generate.go rebuilds cities.tsv.gz from the GeoNames cities dump.
+//
+//	go run generate.go -min-population 100000 -out cities.tsv.gz
+//
+// Admin1 codes are only kept for places in the United States, where GeoNames
+// uses the state abbreviations people write in place names.
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	url := flag.String("url", "https://download.geonames.org/export/dump/cities15000.zip", "GeoNames cities dump to read, as a URL or a local file")
+	minPopulation := flag.Int64("min-population", 100000, "smallest population to include")
+	out := flag.String("out", "cities.tsv.gz", "file to write")
+	flag.Parse()
+
+	body, err := read(*url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var rows [][]string
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			log.Fatal(err)
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+		for scanner.Scan() {
+			fields := strings.Split(scanner.Text(), "\t")
+			if len(fields) < 18 {
+				continue
+			}
+			population, err := strconv.ParseInt(fields[14], 10, 64)
+			if err != nil || population < *minPopulation {
+				continue
+			}
+			admin1 := ""
+			if fields[8] == "US" {
+				admin1 = fields[10]
+			}
+			rows = append(rows, []string{fields[1], fields[2], admin1, fields[8], fields[4], fields[5], fields[14], fields[17]})
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatal(err)
+		}
+		r.Close()
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		for _, k := range []int{3, 2, 1} {
+			if rows[i][k] != rows[j][k] {
+				return rows[i][k] < rows[j][k]
+			}
+		}
+		return false
+	})
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d places to %s", len(rows), *out)
+}
+
+// read returns the contents of source, a URL or a local file, so that a dump
+// downloaded beforehand can be used.
+func read(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", source, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,104 @@
+//go:build ignore
+
+// generate_airports.go rebuilds airports.tsv.gz from the OurAirports data
+// (https://ourairports.com/data/, public domain), keeping large and medium
+// airports with scheduled service and an ICAO code.
+//
+//	go run generate_airports.go -out airports.tsv.gz
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	url := flag.String("url", "https://davidmegginson.github.io/ourairports-data/airports.csv", "OurAirports airports file to read, as a URL or a local file")
+	out := flag.String("out", "airports.tsv.gz", "file to write")
+	flag.Parse()
+
+	body, err := read(*url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(records) == 0 {
+		log.Fatal("no airports")
+	}
+	column := map[string]int{}
+	for i, name := range records[0] {
+		column[name] = i
+	}
+	get := func(record []string, name string) string {
+		return strings.TrimSpace(record[column[name]])
+	}
+
+	var rows [][]string
+	for _, record := range records[1:] {
+		kind := get(record, "type")
+		if kind != "large_airport" && kind != "medium_airport" {
+			continue
+		}
+		if get(record, "scheduled_service") != "yes" {
+			continue
+		}
+		icao := get(record, "gps_code")
+		if len(icao) != 4 {
+			continue
+		}
+		rows = append(rows, []string{
+			get(record, "iata_code"),
+			icao,
+			get(record, "name"),
+			get(record, "municipality"),
+			get(record, "iso_country"),
+			get(record, "latitude_deg"),
+			get(record, "longitude_deg"),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][1] < rows[j][1]
+	})
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d airports to %s", len(rows), *out)
+}
+
+// read returns the contents of source, a URL or a local file, so that a dump
+// downloaded beforehand can be used.
+func read(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", source, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
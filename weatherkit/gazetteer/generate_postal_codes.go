@@ -0,0 +1,102 @@
+//go:build ignore
+
+// generate_postal_codes.go rebuilds postal_codes.tsv.gz from the GeoNames
+// postal code dump.
+//
+//	go run generate_postal_codes.go -countries US,CA,GB,DE,FR,AU,JP -out postal_codes.tsv.gz
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	url := flag.String("url", "https://download.geonames.org/export/zip/allCountries.zip", "GeoNames postal code dump to read, as a URL or a local file")
+	countries := flag.String("countries", "US", "comma separated country codes to include")
+	out := flag.String("out", "postal_codes.tsv.gz", "file to write")
+	flag.Parse()
+
+	include := map[string]bool{}
+	for _, c := range strings.Split(*countries, ",") {
+		include[strings.ToUpper(strings.TrimSpace(c))] = true
+	}
+
+	body, err := read(*url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var rows [][]string
+	for _, f := range archive.File {
+		if !strings.HasSuffix(f.Name, ".txt") {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			log.Fatal(err)
+		}
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			fields := strings.Split(scanner.Text(), "\t")
+			if len(fields) < 11 || !include[fields[0]] || fields[9] == "" || fields[10] == "" {
+				continue
+			}
+			rows = append(rows, []string{fields[0], fields[1], fields[2], fields[4], fields[9], fields[10]})
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatal(err)
+		}
+		r.Close()
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i][0] != rows[j][0] {
+			return rows[i][0] < rows[j][0]
+		}
+		return rows[i][1] < rows[j][1]
+	})
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d postal codes to %s", len(rows), *out)
+}
+
+// read returns the contents of source, a URL or a local file, so that a dump
+// downloaded beforehand can be used.
+func read(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", source, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
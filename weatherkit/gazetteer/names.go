@@ -0,0 +1,111 @@
+package gazetteer
+
+// usStateNames maps the admin1 codes of US places to normalized state names.
+var usStateNames = map[string]string{
+	"AK": "alaska", "AL": "alabama", "AR": "arkansas", "AZ": "arizona",
+	"CA": "california", "CO": "colorado", "CT": "connecticut", "DC": "district of columbia",
+	"DE": "delaware", "FL": "florida", "GA": "georgia", "HI": "hawaii",
+	"IA": "iowa", "ID": "idaho", "IL": "illinois", "IN": "indiana",
+	"KS": "kansas", "KY": "kentucky", "LA": "louisiana", "MA": "massachusetts",
+	"MD": "maryland", "ME": "maine", "MI": "michigan", "MN": "minnesota",
+	"MO": "missouri", "MS": "mississippi", "MT": "montana", "NC": "north carolina",
+	"ND": "north dakota", "NE": "nebraska", "NH": "new hampshire", "NJ": "new jersey",
+	"NM": "new mexico", "NV": "nevada", "NY": "new york", "OH": "ohio",
+	"OK": "oklahoma", "OR": "oregon", "PA": "pennsylvania", "RI": "rhode island",
+	"SC": "south carolina", "SD": "south dakota", "TN": "tennessee", "TX": "texas",
+	"UT": "utah", "VA": "virginia", "VT": "vermont", "WA": "washington",
+	"WI": "wisconsin", "WV": "west virginia", "WY": "wyoming",
+}
+
+// countryNames maps country codes to the normalized names a query may use
+// for the country.
+var countryNames = map[string][]string{
+	"AE": {"united arab emirates", "uae"},
+	"AO": {"angola"},
+	"AR": {"argentina"},
+	"AT": {"austria"},
+	"AU": {"australia"},
+	"BD": {"bangladesh"},
+	"BE": {"belgium"},
+	"BO": {"bolivia"},
+	"BR": {"brazil"},
+	"CA": {"canada"},
+	"CD": {"democratic republic of the congo", "dr congo", "drc"},
+	"CH": {"switzerland"},
+	"CL": {"chile"},
+	"CN": {"china"},
+	"CO": {"colombia"},
+	"CR": {"costa rica"},
+	"CU": {"cuba"},
+	"CZ": {"czechia", "czech republic"},
+	"DE": {"germany"},
+	"DK": {"denmark"},
+	"DO": {"dominican republic"},
+	"DZ": {"algeria"},
+	"EC": {"ecuador"},
+	"EG": {"egypt"},
+	"ES": {"spain"},
+	"ET": {"ethiopia"},
+	"FI": {"finland"},
+	"FR": {"france"},
+	"GB": {"united kingdom", "uk", "great britain", "england", "scotland", "wales", "northern ireland"},
+	"GH": {"ghana"},
+	"GR": {"greece"},
+	"GT": {"guatemala"},
+	"HK": {"hong kong"},
+	"HU": {"hungary"},
+	"ID": {"indonesia"},
+	"IE": {"ireland"},
+	"IL": {"israel"},
+	"IN": {"india"},
+	"IQ": {"iraq"},
+	"IR": {"iran"},
+	"IS": {"iceland"},
+	"IT": {"italy"},
+	"JM": {"jamaica"},
+	"JO": {"jordan"},
+	"JP": {"japan"},
+	"KE": {"kenya"},
+	"KR": {"south korea", "korea"},
+	"KW": {"kuwait"},
+	"KZ": {"kazakhstan"},
+	"LB": {"lebanon"},
+	"LK": {"sri lanka"},
+	"MA": {"morocco"},
+	"MN": {"mongolia"},
+	"MX": {"mexico"},
+	"MY": {"malaysia"},
+	"NG": {"nigeria"},
+	"NL": {"netherlands", "the netherlands", "holland"},
+	"NO": {"norway"},
+	"NP": {"nepal"},
+	"NZ": {"new zealand"},
+	"PA": {"panama"},
+	"PE": {"peru"},
+	"PH": {"philippines"},
+	"PK": {"pakistan"},
+	"PL": {"poland"},
+	"PR": {"puerto rico"},
+	"PT": {"portugal"},
+	"PY": {"paraguay"},
+	"QA": {"qatar"},
+	"RO": {"romania"},
+	"RU": {"russia"},
+	"SA": {"saudi arabia"},
+	"SD": {"sudan"},
+	"SE": {"sweden"},
+	"SG": {"singapore"},
+	"SN": {"senegal"},
+	"TH": {"thailand"},
+	"TN": {"tunisia"},
+	"TR": {"turkey", "turkiye"},
+	"TW": {"taiwan"},
+	"TZ": {"tanzania"},
+	"UA": {"ukraine"},
+	"US": {"united states", "united states of america", "usa", "america"},
+	"UY": {"uruguay"},
+	"UZ": {"uzbekistan"},
+	"VE": {"venezuela"},
+	"VN": {"vietnam", "viet nam"},
+	"ZA": {"south africa"},
+}
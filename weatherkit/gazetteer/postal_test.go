@@ -0,0 +1,151 @@
+package gazetteer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookupPostalCode(t *testing.T) {
+	// Postal code centroids vary between releases of the GeoNames dump, so
+	// coordinates are checked to about 5 km.
+	const tolerance = 0.05
+	tests := []struct {
+		query               string
+		code, place         string
+		country             string
+		latitude, longitude float64
+	}{
+		{"48104", "48104", "Ann Arbor", "US", 42.265, -83.718},
+		{" 10001 , us ", "10001", "New York", "US", 40.751, -73.997},
+		{"02139, United States", "02139", "Cambridge", "US", 42.365, -71.104},
+		// Only the outward code of a British postcode is in the dataset,
+		// whether the postcode is written with its space or without.
+		{"SW1A 1AA", "SW1A", "London", "GB", 51.501, -0.142},
+		{"sw1a1aa", "SW1A", "London", "GB", 51.501, -0.142},
+		{"SW1A, GB", "SW1A", "London", "GB", 51.501, -0.142},
+		{"EH1 1YZ, United Kingdom", "EH1", "Edinburgh", "GB", 55.950, -3.188},
+		// Canadian postal codes are stored by forward sortation area.
+		{"H3B 1A1", "H3B", "Montreal", "CA", 45.501, -73.570},
+		{"H3B1A1, Canada", "H3B", "Montreal", "CA", 45.501, -73.570},
+		// Codes used in several countries are told apart by the country.
+		{"75001, FR", "75001", "Paris", "FR", 48.863, 2.337},
+		{"75001, France", "75001", "Paris", "FR", 48.863, 2.337},
+	}
+	for _, tt := range tests {
+		matches, err := LookupPostalCode(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 1 {
+			t.Errorf("LookupPostalCode(%q) returned %d postal codes, want 1", tt.query, len(matches))
+			continue
+		}
+		p := matches[0]
+		if p.PostalCode != tt.code || p.PlaceName != tt.place || p.CountryCode != tt.country {
+			t.Errorf("LookupPostalCode(%q) = %s, want %s %s, %s", tt.query, p.DisplayName(), tt.code, tt.place, tt.country)
+			continue
+		}
+		if math.Abs(p.Latitude-tt.latitude) > tolerance || math.Abs(p.Longitude-tt.longitude) > tolerance {
+			t.Errorf("LookupPostalCode(%q) = %g, %g, want %g, %g", tt.query, p.Latitude, p.Longitude, tt.latitude, tt.longitude)
+		}
+	}
+}
+
+func TestLookupPostalCodeCountries(t *testing.T) {
+	// Without a country every country using the code is returned.
+	matches, err := LookupPostalCode("75001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	countries := map[string]bool{}
+	for _, p := range matches {
+		if p.PostalCode != "75001" {
+			t.Errorf("LookupPostalCode(75001) returned %s", p.DisplayName())
+		}
+		countries[p.CountryCode] = true
+	}
+	if !countries["FR"] {
+		t.Errorf("LookupPostalCode(75001) = %v, want the French postal code among them", matches)
+	}
+
+	for _, query := range []string{
+		"",
+		"00000",
+		"75001, DE",
+		"48104, Canada",
+		"ZZ9 9ZZ",
+	} {
+		matches, err := LookupPostalCode(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 0 {
+			t.Errorf("LookupPostalCode(%q) = %v, want no postal codes", query, matches)
+		}
+	}
+}
+
+func TestOutwardCode(t *testing.T) {
+	tests := []struct {
+		code, want string
+		ok         bool
+	}{
+		{"SW1A1AA", "SW1A", true},
+		{"EC1A1BB", "EC1A", true},
+		{"M11AE", "M1", true},
+		{"H3B1A1", "H3B", true},
+		{"48104", "", false},
+		{"1012AB", "", false},
+		{"SW1A", "", false},
+		{"SW1AAAA", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := outwardCode(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("outwardCode(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLookupAirport(t *testing.T) {
+	// Expected coordinates are those of the OurAirports data.
+	tests := []struct {
+		code                string
+		iata, icao          string
+		municipality        string
+		country             string
+		latitude, longitude float64
+	}{
+		{"LHR", "LHR", "EGLL", "London", "GB", 51.4706, -0.461941},
+		{"EGLL", "LHR", "EGLL", "London", "GB", 51.4706, -0.461941},
+		{"lhr", "LHR", "EGLL", "London", "GB", 51.4706, -0.461941},
+		{" egll ", "LHR", "EGLL", "London", "GB", 51.4706, -0.461941},
+		{"JFK", "JFK", "KJFK", "New York", "US", 40.639801, -73.7789},
+		{"kdtw", "DTW", "KDTW", "Detroit", "US", 42.212399, -83.353401},
+		{"NRT", "NRT", "RJAA", "Tokyo", "JP", 35.764702, 140.386002},
+	}
+	for _, tt := range tests {
+		a, ok, err := LookupAirport(tt.code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("LookupAirport(%q) found nothing, want %s", tt.code, tt.icao)
+			continue
+		}
+		if a.IataCode != tt.iata || a.IcaoCode != tt.icao || a.Municipality != tt.municipality || a.CountryCode != tt.country {
+			t.Errorf("LookupAirport(%q) = %s in %s, %s, want %s/%s in %s, %s", tt.code, a.DisplayName(), a.Municipality, a.CountryCode, tt.iata, tt.icao, tt.municipality, tt.country)
+			continue
+		}
+		if math.Abs(a.Latitude-tt.latitude) > coordinateTolerance || math.Abs(a.Longitude-tt.longitude) > coordinateTolerance {
+			t.Errorf("LookupAirport(%q) = %g, %g, want %g, %g", tt.code, a.Latitude, a.Longitude, tt.latitude, tt.longitude)
+		}
+	}
+
+	// Codes must be three letter IATA or four letter ICAO codes.
+	for _, code := range []string{"", "LH", "ZZZ", "ZZZZ", "KDTWX", "EGL"} {
+		if a, ok, err := LookupAirport(code); err != nil || ok {
+			t.Errorf("LookupAirport(%q) = %s, %v, %v, want no airport", code, a.DisplayName(), ok, err)
+		}
+	}
+}
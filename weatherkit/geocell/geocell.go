@@ -0,0 +1,10 @@
+// Package geocell decodes the identifiers of geohash, H3 and Open Location
+// Code (plus code) grid cells to the coordinates of the cell centroid.
+//
+// Latitudes and longitudes are in degrees, with north and east positive.
+package geocell
+
+import "errors"
+
+// ErrInvalid is wrapped by the errors returned for malformed cell identifiers.
+var ErrInvalid = errors.New("invalid cell")
@@ -0,0 +1,116 @@
+package geocell
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-6
+
+func TestDecodeGeohash(t *testing.T) {
+	tests := []struct {
+		hash                string
+		latitude, longitude float64
+	}{
+		// Examples from the geohash specification.
+		{"u4pruydqqvj", 57.649110630, 10.407439694},
+		{"ezs42", 42.604980469, -5.603027344},
+		{"9q8yy", 37.770996094, -122.409667969},
+		// Upper case is accepted.
+		{"EZS42", 42.604980469, -5.603027344},
+	}
+	for _, tt := range tests {
+		latitude, longitude, err := DecodeGeohash(tt.hash)
+		if err != nil {
+			t.Errorf("DecodeGeohash(%q) returned error: %v", tt.hash, err)
+			continue
+		}
+		if math.Abs(latitude-tt.latitude) > tolerance || math.Abs(longitude-tt.longitude) > tolerance {
+			t.Errorf("DecodeGeohash(%q) = %.9f, %.9f, want %.9f, %.9f", tt.hash, latitude, longitude, tt.latitude, tt.longitude)
+		}
+	}
+}
+
+func TestGeohashBounds(t *testing.T) {
+	minLat, minLon, maxLat, maxLon, err := GeohashBounds("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [4]float64{42.583007812, -5.625, 42.626953125, -5.581054688}
+	got := [4]float64{minLat, minLon, maxLat, maxLon}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > tolerance {
+			t.Fatalf("GeohashBounds(ezs42) = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDecodeH3(t *testing.T) {
+	tests := []struct {
+		index               string
+		resolution          int
+		latitude, longitude float64
+	}{
+		// Cell centers from the H3 documentation.
+		{"8928308280fffff", 9, 37.776702349, -122.418459323},
+		{"85283473fffffff", 5, 37.345793375, -121.976375973},
+	}
+	for _, tt := range tests {
+		latitude, longitude, err := DecodeH3(tt.index)
+		if err != nil {
+			t.Errorf("DecodeH3(%q) returned error: %v", tt.index, err)
+			continue
+		}
+		if math.Abs(latitude-tt.latitude) > tolerance || math.Abs(longitude-tt.longitude) > tolerance {
+			t.Errorf("DecodeH3(%q) = %.9f, %.9f, want %.9f, %.9f", tt.index, latitude, longitude, tt.latitude, tt.longitude)
+		}
+		resolution, err := H3Resolution(tt.index)
+		if err != nil || resolution != tt.resolution {
+			t.Errorf("H3Resolution(%q) = %d, %v, want %d", tt.index, resolution, err, tt.resolution)
+		}
+	}
+}
+
+func TestDecodePlusCode(t *testing.T) {
+	tests := []struct {
+		code                string
+		latitude, longitude float64
+	}{
+		// Examples from the Open Location Code documentation and test data.
+		{"849VCWC8+R9", 37.4220625, -122.0840625},
+		{"9C3W9QCJ+2VX", 51.3701125, -1.217765625},
+		// Padded codes decode to the center of the larger cell.
+		{"8FVC0000+", 47.5, 8.5},
+	}
+	for _, tt := range tests {
+		latitude, longitude, err := DecodePlusCode(tt.code)
+		if err != nil {
+			t.Errorf("DecodePlusCode(%q) returned error: %v", tt.code, err)
+			continue
+		}
+		if math.Abs(latitude-tt.latitude) > tolerance || math.Abs(longitude-tt.longitude) > tolerance {
+			t.Errorf("DecodePlusCode(%q) = %.9f, %.9f, want %.9f, %.9f", tt.code, latitude, longitude, tt.latitude, tt.longitude)
+		}
+	}
+}
+
+func TestInvalidCells(t *testing.T) {
+	decoders := map[string]func(string) (float64, float64, error){
+		"geohash":   DecodeGeohash,
+		"h3":        DecodeH3,
+		"plus code": DecodePlusCode,
+	}
+	invalid := map[string][]string{
+		"geohash":   {"", "ezs4a", "u4pr!"},
+		"h3":        {"", "xyz", "0"},
+		"plus code": {"", "CWC8+R9", "849VCWC8R9", "849VCWC8+R"},
+	}
+	for kind, values := range invalid {
+		for _, value := range values {
+			if _, _, err := decoders[kind](value); !errors.Is(err, ErrInvalid) {
+				t.Errorf("decoding %s %q: got error %v, want ErrInvalid", kind, value, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package geocell
+
+import (
+	"fmt"
+	"strings"
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashBounds returns the bounding box of a geohash cell.
+func GeohashBounds(hash string) (minLat, minLon, maxLat, maxLon float64, err error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" || len(hash) > 22 {
+		return 0, 0, 0, 0, fmt.Errorf("%w: geohash %q must have 1 to 22 characters", ErrInvalid, hash)
+	}
+	minLat, maxLat = -90, 90
+	minLon, maxLon = -180, 180
+	even := true
+	for _, c := range hash {
+		index := strings.IndexRune(geohashAlphabet, c)
+		if index < 0 {
+			return 0, 0, 0, 0, fmt.Errorf("%w: geohash %q contains %q", ErrInvalid, hash, c)
+		}
+		// Bits alternate between longitude and latitude, starting with longitude.
+		for bit := 4; bit >= 0; bit-- {
+			set := index>>bit&1 == 1
+			if even {
+				mid := (minLon + maxLon) / 2
+				if set {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return minLat, minLon, maxLat, maxLon, nil
+}
+
+// DecodeGeohash returns the centroid of a geohash cell.
+func DecodeGeohash(hash string) (latitude, longitude float64, err error) {
+	minLat, minLon, maxLat, maxLon, err := GeohashBounds(hash)
+	if err != nil {
+		return 0, 0, err
+	}
+	return (minLat + maxLat) / 2, (minLon + maxLon) / 2, nil
+}
@@ -0,0 +1,336 @@
+package geocell
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The H3 decoder follows the reference implementation
+// (https://github.com/uber/h3). A cell index holds a base cell, one of 122
+// resolution 0 cells on the faces of an icosahedron, and a digit for each
+// finer resolution giving the child cell within its parent. The index is
+// decoded to i, j, k coordinates on a face of the icosahedron, which are
+// projected back to the sphere with the gnomonic projection centered on the
+// face.
+
+const (
+	numBaseCells = 122
+	numFaces     = 20
+	maxH3Res     = 15
+
+	sqrt7 = 2.6457513110645905905
+	// res0UGnomonic is the scaling factor from unit length in the resolution
+	// 0 grid to the gnomonic projection.
+	res0UGnomonic = 0.38196601125010500003
+	// ap7RotRads is the rotation between the Class II and Class III grids.
+	ap7RotRads = 0.333473172251832115336090755351601070065900389
+)
+
+// Digits of an index giving the direction of a child from its parent.
+const (
+	centerDigit  = 0
+	iAxesDigit   = 4
+	ikAxesDigit  = 5
+	invalidDigit = 7
+)
+
+type coordIJK struct {
+	i, j, k int
+}
+
+type faceIJK struct {
+	face  int
+	coord coordIJK
+}
+
+// faceOrientIJK is the face across an edge of a face, and how to translate
+// and rotate coordinates into its coordinate system.
+type faceOrientIJK struct {
+	face      int
+	translate coordIJK
+	ccwRot60  int
+}
+
+var unitVecs = [7]coordIJK{{0, 0, 0}, {0, 0, 1}, {0, 1, 0}, {0, 1, 1}, {1, 0, 0}, {1, 0, 1}, {1, 1, 0}}
+
+func (c coordIJK) add(o coordIJK) coordIJK {
+	return coordIJK{c.i + o.i, c.j + o.j, c.k + o.k}
+}
+
+func (c coordIJK) sub(o coordIJK) coordIJK {
+	return coordIJK{c.i - o.i, c.j - o.j, c.k - o.k}
+}
+
+func (c coordIJK) scale(factor int) coordIJK {
+	return coordIJK{c.i * factor, c.j * factor, c.k * factor}
+}
+
+// normalize returns the equivalent coordinates with no negative components
+// and at least one zero component.
+func (c coordIJK) normalize() coordIJK {
+	if c.i < 0 {
+		c.j -= c.i
+		c.k -= c.i
+		c.i = 0
+	}
+	if c.j < 0 {
+		c.i -= c.j
+		c.k -= c.j
+		c.j = 0
+	}
+	if c.k < 0 {
+		c.i -= c.k
+		c.j -= c.k
+		c.k = 0
+	}
+	least := c.i
+	if c.j < least {
+		least = c.j
+	}
+	if c.k < least {
+		least = c.k
+	}
+	return coordIJK{c.i - least, c.j - least, c.k - least}
+}
+
+// combine returns i*iVec + j*jVec + k*kVec, normalized.
+func (c coordIJK) combine(iVec, jVec, kVec coordIJK) coordIJK {
+	return iVec.scale(c.i).add(jVec.scale(c.j)).add(kVec.scale(c.k)).normalize()
+}
+
+func (c coordIJK) rotate60ccw() coordIJK {
+	return c.combine(coordIJK{1, 1, 0}, coordIJK{0, 1, 1}, coordIJK{1, 0, 1})
+}
+
+func (c coordIJK) rotate60cw() coordIJK {
+	return c.combine(coordIJK{1, 0, 1}, coordIJK{1, 1, 0}, coordIJK{0, 1, 1})
+}
+
+// downAp7 returns the coordinates of the center of the cell in the next
+// finer, Class III, resolution.
+func (c coordIJK) downAp7() coordIJK {
+	return c.combine(coordIJK{3, 0, 1}, coordIJK{1, 3, 0}, coordIJK{0, 1, 3})
+}
+
+// downAp7r returns the coordinates of the center of the cell in the next
+// finer, Class II, resolution.
+func (c coordIJK) downAp7r() coordIJK {
+	return c.combine(coordIJK{3, 1, 0}, coordIJK{0, 3, 1}, coordIJK{1, 0, 3})
+}
+
+// upAp7r returns the coordinates of the containing cell in the next coarser,
+// Class II, resolution.
+func (c coordIJK) upAp7r() coordIJK {
+	i := float64(c.i - c.k)
+	j := float64(c.j - c.k)
+	return coordIJK{int(math.Round((2*i + j) / 7)), int(math.Round((3*j - i) / 7)), 0}.normalize()
+}
+
+func (c coordIJK) hex2d() (x, y float64) {
+	i := float64(c.i - c.k)
+	j := float64(c.j - c.k)
+	return i - 0.5*j, j * math.Sqrt(3) / 2
+}
+
+func isClassIII(res int) bool {
+	return res%2 == 1
+}
+
+func isPentagon(baseCell int) bool {
+	switch baseCell {
+	case 4, 14, 24, 38, 49, 58, 63, 72, 83, 97, 107, 117:
+		return true
+	}
+	return false
+}
+
+// h3Index is a parsed H3 cell index.
+type h3Index struct {
+	res      int
+	baseCell int
+	digits   [maxH3Res + 1]int
+}
+
+// parseH3 parses and validates a cell index given in hexadecimal.
+func parseH3(s string) (h3Index, error) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "0x")
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: h3 index %q %s", ErrInvalid, s, reason)
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return h3Index{}, invalid("is not a hexadecimal number")
+	}
+	if v>>63 != 0 || v>>59&0xf != 1 {
+		return h3Index{}, invalid("is not a cell index")
+	}
+	h := h3Index{res: int(v >> 52 & 0xf), baseCell: int(v >> 45 & 0x7f)}
+	if h.baseCell >= numBaseCells {
+		return h3Index{}, invalid("has an invalid base cell")
+	}
+	for r := 1; r <= maxH3Res; r++ {
+		h.digits[r] = int(v >> ((maxH3Res - r) * 3) & 7)
+		if (r <= h.res) != (h.digits[r] != invalidDigit) {
+			return h3Index{}, invalid("has invalid digits")
+		}
+	}
+	if isPentagon(h.baseCell) && h.leadingNonZeroDigit() == 1 {
+		return h3Index{}, invalid("is a deleted pentagon child")
+	}
+	return h, nil
+}
+
+func (h h3Index) leadingNonZeroDigit() int {
+	for r := 1; r <= h.res; r++ {
+		if h.digits[r] != centerDigit {
+			return h.digits[r]
+		}
+	}
+	return centerDigit
+}
+
+// rotate60cw rotates every digit of the index 60 degrees clockwise.
+func (h h3Index) rotate60cw() h3Index {
+	rotated := [7]int{0, 3, 6, 2, 5, 1, 4}
+	for r := 1; r <= h.res; r++ {
+		h.digits[r] = rotated[h.digits[r]]
+	}
+	return h
+}
+
+// faceIJK returns the coordinates of the cell center on the face of the
+// icosahedron it lies on.
+func (h h3Index) faceIJK() faceIJK {
+	pentagon := isPentagon(h.baseCell)
+	if pentagon && h.leadingNonZeroDigit() == ikAxesDigit {
+		h = h.rotate60cw()
+	}
+	fijk := baseCells[h.baseCell]
+	// Only cells of pentagons and of hexagons off the face center can cross
+	// onto a neighboring face.
+	possibleOverage := pentagon || (h.res != 0 && fijk.coord != coordIJK{})
+	for r := 1; r <= h.res; r++ {
+		if isClassIII(r) {
+			fijk.coord = fijk.coord.downAp7()
+		} else {
+			fijk.coord = fijk.coord.downAp7r()
+		}
+		fijk.coord = fijk.coord.add(unitVecs[h.digits[r]]).normalize()
+	}
+	if !possibleOverage {
+		return fijk
+	}
+
+	original := fijk.coord
+	res := h.res
+	if isClassIII(res) {
+		// Overage is handled in the Class II grid.
+		fijk.coord = fijk.coord.downAp7r()
+		res++
+	}
+	pentLeading4 := pentagon && h.leadingNonZeroDigit() == iAxesDigit
+	if fijk.adjustOverage(res, pentLeading4) {
+		if pentagon {
+			for fijk.adjustOverage(res, false) {
+			}
+		}
+		if res != h.res {
+			fijk.coord = fijk.coord.upAp7r()
+		}
+	} else if res != h.res {
+		fijk.coord = original
+	}
+	return fijk
+}
+
+// adjustOverage moves coordinates that lie beyond the edge of the face onto
+// the neighboring face, and reports whether it did.
+func (f *faceIJK) adjustOverage(res int, pentLeading4 bool) bool {
+	// The number of Class II cells along an edge of a face, and the size of
+	// the resolution 0 unit, at this resolution.
+	unitScale := int(math.Pow(7, float64(res/2)))
+	maxDim := 2 * unitScale
+	c := f.coord
+	if c.i+c.j+c.k <= maxDim {
+		return false
+	}
+	var orient faceOrientIJK
+	switch {
+	case c.k > 0 && c.j > 0:
+		orient = faceNeighbors[f.face][2]
+	case c.k > 0:
+		orient = faceNeighbors[f.face][1]
+		if pentLeading4 {
+			// Rotate out of the missing k axes subsequence of the pentagon.
+			origin := coordIJK{maxDim, 0, 0}
+			c = c.sub(origin).normalize().rotate60cw().add(origin).normalize()
+		}
+	default:
+		orient = faceNeighbors[f.face][0]
+	}
+	for i := 0; i < orient.ccwRot60; i++ {
+		c = c.rotate60ccw()
+	}
+	f.face = orient.face
+	f.coord = c.add(orient.translate.scale(unitScale)).normalize()
+	return true
+}
+
+// geo projects face coordinates at a resolution back to the sphere.
+func (f faceIJK) geo(res int) (latitude, longitude float64) {
+	x, y := f.coord.hex2d()
+	center := faceCenterGeo[f.face]
+	r := math.Hypot(x, y)
+	if r < 1e-16 {
+		return center[0] / rad, center[1] / rad
+	}
+	theta := math.Atan2(y, x)
+	r /= math.Pow(sqrt7, float64(res))
+	r = math.Atan(r * res0UGnomonic)
+	if isClassIII(res) {
+		theta = posAngle(theta + ap7RotRads)
+	}
+	azimuth := posAngle(faceAxesAzRadsCII[f.face] - theta)
+	lat1, lon1 := center[0], center[1]
+	sinLat := math.Sin(lat1)*math.Cos(r) + math.Cos(lat1)*math.Sin(r)*math.Cos(azimuth)
+	lat := math.Asin(math.Max(-1, math.Min(1, sinLat)))
+	lon := lon1 + math.Atan2(math.Sin(azimuth)*math.Sin(r)*math.Cos(lat1), math.Cos(r)-math.Sin(lat1)*math.Sin(lat))
+	return lat / rad, normalizeLongitude(lon / rad)
+}
+
+const rad = math.Pi / 180
+
+func posAngle(a float64) float64 {
+	a = math.Mod(a, 2*math.Pi)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a
+}
+
+func normalizeLongitude(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// DecodeH3 returns the centroid of an H3 cell given as a hexadecimal index
+// such as "8928308280fffff".
+func DecodeH3(index string) (latitude, longitude float64, err error) {
+	h, err := parseH3(index)
+	if err != nil {
+		return 0, 0, err
+	}
+	latitude, longitude = h.faceIJK().geo(h.res)
+	return latitude, longitude, nil
+}
+
+// H3Resolution returns the resolution, from 0 to 15, of an H3 cell.
+func H3Resolution(index string) (int, error) {
+	h, err := parseH3(index)
+	return h.res, err
+}
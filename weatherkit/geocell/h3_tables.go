@@ -0,0 +1,202 @@
+package geocell
+
+// Tables of the icosahedron and its base cells, as in the H3 reference
+// implementation.
+
+// faceCenterGeo is the latitude and longitude, in radians, of the center of each face.
+var faceCenterGeo = [numFaces][2]float64{
+	{0.80358264971899, 1.2483974196173961},      // 0
+	{1.3077478834556382, 2.5369450098779214},    // 1
+	{1.054751253523952, -1.3475173589003966},    // 2
+	{0.6001915955381868, -0.45060390946975576},  // 3
+	{0.49171542819877384, 0.40198820291130694},  // 4
+	{0.1727453274156187, 1.6781468852804338},    // 5
+	{0.6059293215713507, 2.9539233298124117},    // 6
+	{0.42737051832897965, -1.8888762003362853},  // 7
+	{-0.07906611854921283, -0.7334295133808677}, // 8
+	{-0.23096164445538364, 0.506495587332349},   // 9
+	{0.07906611854921283, 2.4081631402089254},   // 10
+	{0.23096164445538364, -2.635097066257444},   // 11
+	{-0.1727453274156187, -1.4634457683093596},  // 12
+	{-0.6059293215713507, -0.18766932377738163}, // 13
+	{-0.42737051832897965, 1.2527164532535078},  // 14
+	{-0.6001915955381868, 2.6909887441200375},   // 15
+	{-0.49171542819877384, -2.7396044506784865}, // 16
+	{-0.80358264971899, -1.8931952339723972},    // 17
+	{-1.3077478834556382, -0.6046476437118721},  // 18
+	{-1.054751253523952, 1.7940752946893965},    // 19
+}
+
+// faceAxesAzRadsCII is the azimuth, in radians, of the Class II i axis of each face.
+var faceAxesAzRadsCII = [numFaces]float64{
+	5.6199582685239395,  // 0
+	5.7603390817141875,  // 1
+	0.78021365439343,    // 2
+	0.4304693639799999,  // 3
+	6.130269123335111,   // 4
+	2.692877706530643,   // 5
+	2.982963003477244,   // 6
+	3.532912002790141,   // 7
+	3.494305004259568,   // 8
+	3.0032141694995382,  // 9
+	5.930472956509812,   // 10
+	0.13837848409025486, // 11
+	0.4487149470591504,  // 12
+	0.15862965011254937, // 13
+	5.891865957979238,   // 14
+	2.711123289609793,   // 15
+	3.294508837434268,   // 16
+	3.80481969224544,    // 17
+	3.6644388790551923,  // 18
+	2.361378999196363,   // 19
+}
+
+// faceNeighbors is the face across the ij, ki and jk edges of each face.
+var faceNeighbors = [numFaces][3]faceOrientIJK{
+	{{4, coordIJK{2, 0, 2}, 1}, {1, coordIJK{2, 2, 0}, 5}, {5, coordIJK{0, 2, 2}, 3}},    // 0
+	{{0, coordIJK{2, 0, 2}, 1}, {2, coordIJK{2, 2, 0}, 5}, {6, coordIJK{0, 2, 2}, 3}},    // 1
+	{{1, coordIJK{2, 0, 2}, 1}, {3, coordIJK{2, 2, 0}, 5}, {7, coordIJK{0, 2, 2}, 3}},    // 2
+	{{2, coordIJK{2, 0, 2}, 1}, {4, coordIJK{2, 2, 0}, 5}, {8, coordIJK{0, 2, 2}, 3}},    // 3
+	{{3, coordIJK{2, 0, 2}, 1}, {0, coordIJK{2, 2, 0}, 5}, {9, coordIJK{0, 2, 2}, 3}},    // 4
+	{{10, coordIJK{2, 2, 0}, 3}, {14, coordIJK{2, 0, 2}, 3}, {0, coordIJK{0, 2, 2}, 3}},  // 5
+	{{11, coordIJK{2, 2, 0}, 3}, {10, coordIJK{2, 0, 2}, 3}, {1, coordIJK{0, 2, 2}, 3}},  // 6
+	{{12, coordIJK{2, 2, 0}, 3}, {11, coordIJK{2, 0, 2}, 3}, {2, coordIJK{0, 2, 2}, 3}},  // 7
+	{{13, coordIJK{2, 2, 0}, 3}, {12, coordIJK{2, 0, 2}, 3}, {3, coordIJK{0, 2, 2}, 3}},  // 8
+	{{14, coordIJK{2, 2, 0}, 3}, {13, coordIJK{2, 0, 2}, 3}, {4, coordIJK{0, 2, 2}, 3}},  // 9
+	{{5, coordIJK{2, 2, 0}, 3}, {6, coordIJK{2, 0, 2}, 3}, {15, coordIJK{0, 2, 2}, 3}},   // 10
+	{{6, coordIJK{2, 2, 0}, 3}, {7, coordIJK{2, 0, 2}, 3}, {16, coordIJK{0, 2, 2}, 3}},   // 11
+	{{7, coordIJK{2, 2, 0}, 3}, {8, coordIJK{2, 0, 2}, 3}, {17, coordIJK{0, 2, 2}, 3}},   // 12
+	{{8, coordIJK{2, 2, 0}, 3}, {9, coordIJK{2, 0, 2}, 3}, {18, coordIJK{0, 2, 2}, 3}},   // 13
+	{{9, coordIJK{2, 2, 0}, 3}, {5, coordIJK{2, 0, 2}, 3}, {19, coordIJK{0, 2, 2}, 3}},   // 14
+	{{16, coordIJK{2, 0, 2}, 1}, {19, coordIJK{2, 2, 0}, 5}, {10, coordIJK{0, 2, 2}, 3}}, // 15
+	{{17, coordIJK{2, 0, 2}, 1}, {15, coordIJK{2, 2, 0}, 5}, {11, coordIJK{0, 2, 2}, 3}}, // 16
+	{{18, coordIJK{2, 0, 2}, 1}, {16, coordIJK{2, 2, 0}, 5}, {12, coordIJK{0, 2, 2}, 3}}, // 17
+	{{19, coordIJK{2, 0, 2}, 1}, {17, coordIJK{2, 2, 0}, 5}, {13, coordIJK{0, 2, 2}, 3}}, // 18
+	{{15, coordIJK{2, 0, 2}, 1}, {18, coordIJK{2, 2, 0}, 5}, {14, coordIJK{0, 2, 2}, 3}}, // 19
+}
+
+// baseCells is the home face and resolution 0 coordinates of each base cell.
+var baseCells = [numBaseCells]faceIJK{
+	{1, coordIJK{1, 0, 0}},  // 0
+	{2, coordIJK{1, 1, 0}},  // 1
+	{1, coordIJK{0, 0, 0}},  // 2
+	{2, coordIJK{1, 0, 0}},  // 3
+	{0, coordIJK{2, 0, 0}},  // 4
+	{1, coordIJK{1, 1, 0}},  // 5
+	{1, coordIJK{0, 0, 1}},  // 6
+	{2, coordIJK{0, 0, 0}},  // 7
+	{0, coordIJK{1, 0, 0}},  // 8
+	{2, coordIJK{0, 1, 0}},  // 9
+	{1, coordIJK{0, 1, 0}},  // 10
+	{1, coordIJK{0, 1, 1}},  // 11
+	{3, coordIJK{1, 0, 0}},  // 12
+	{3, coordIJK{1, 1, 0}},  // 13
+	{11, coordIJK{2, 0, 0}}, // 14
+	{4, coordIJK{1, 0, 0}},  // 15
+	{0, coordIJK{0, 0, 0}},  // 16
+	{6, coordIJK{0, 1, 0}},  // 17
+	{0, coordIJK{0, 0, 1}},  // 18
+	{2, coordIJK{0, 1, 1}},  // 19
+	{7, coordIJK{0, 0, 1}},  // 20
+	{2, coordIJK{0, 0, 1}},  // 21
+	{0, coordIJK{1, 1, 0}},  // 22
+	{6, coordIJK{0, 0, 1}},  // 23
+	{10, coordIJK{2, 0, 0}}, // 24
+	{6, coordIJK{0, 0, 0}},  // 25
+	{3, coordIJK{0, 0, 0}},  // 26
+	{11, coordIJK{1, 0, 0}}, // 27
+	{4, coordIJK{1, 1, 0}},  // 28
+	{3, coordIJK{0, 1, 0}},  // 29
+	{0, coordIJK{0, 1, 1}},  // 30
+	{4, coordIJK{0, 0, 0}},  // 31
+	{5, coordIJK{0, 1, 0}},  // 32
+	{0, coordIJK{0, 1, 0}},  // 33
+	{7, coordIJK{0, 1, 0}},  // 34
+	{6, coordIJK{1, 1, 0}},  // 35
+	{7, coordIJK{0, 0, 0}},  // 36
+	{10, coordIJK{1, 0, 0}}, // 37
+	{12, coordIJK{2, 0, 0}}, // 38
+	{6, coordIJK{1, 0, 1}},  // 39
+	{7, coordIJK{1, 0, 1}},  // 40
+	{4, coordIJK{0, 0, 1}},  // 41
+	{3, coordIJK{0, 0, 1}},  // 42
+	{3, coordIJK{0, 1, 1}},  // 43
+	{4, coordIJK{0, 1, 0}},  // 44
+	{6, coordIJK{1, 0, 0}},  // 45
+	{11, coordIJK{0, 0, 0}}, // 46
+	{8, coordIJK{0, 0, 1}},  // 47
+	{5, coordIJK{0, 0, 1}},  // 48
+	{14, coordIJK{2, 0, 0}}, // 49
+	{5, coordIJK{0, 0, 0}},  // 50
+	{12, coordIJK{1, 0, 0}}, // 51
+	{5, coordIJK{1, 1, 0}},  // 52
+	{4, coordIJK{0, 1, 1}},  // 53
+	{7, coordIJK{1, 1, 0}},  // 54
+	{7, coordIJK{1, 0, 0}},  // 55
+	{11, coordIJK{0, 1, 0}}, // 56
+	{10, coordIJK{0, 0, 0}}, // 57
+	{13, coordIJK{2, 0, 0}}, // 58
+	{10, coordIJK{0, 0, 1}}, // 59
+	{11, coordIJK{0, 0, 1}}, // 60
+	{9, coordIJK{0, 1, 0}},  // 61
+	{8, coordIJK{0, 1, 0}},  // 62
+	{6, coordIJK{2, 0, 0}},  // 63
+	{8, coordIJK{0, 0, 0}},  // 64
+	{9, coordIJK{0, 0, 1}},  // 65
+	{14, coordIJK{1, 0, 0}}, // 66
+	{5, coordIJK{1, 0, 1}},  // 67
+	{11, coordIJK{0, 1, 1}}, // 68
+	{8, coordIJK{1, 0, 1}},  // 69
+	{5, coordIJK{1, 0, 0}},  // 70
+	{12, coordIJK{0, 0, 0}}, // 71
+	{7, coordIJK{2, 0, 0}},  // 72
+	{12, coordIJK{0, 1, 0}}, // 73
+	{10, coordIJK{0, 1, 0}}, // 74
+	{9, coordIJK{0, 0, 0}},  // 75
+	{13, coordIJK{1, 0, 0}}, // 76
+	{16, coordIJK{0, 0, 1}}, // 77
+	{10, coordIJK{0, 1, 1}}, // 78
+	{15, coordIJK{0, 1, 0}}, // 79
+	{16, coordIJK{0, 1, 0}}, // 80
+	{9, coordIJK{1, 1, 0}},  // 81
+	{8, coordIJK{1, 1, 0}},  // 82
+	{5, coordIJK{2, 0, 0}},  // 83
+	{8, coordIJK{1, 0, 0}},  // 84
+	{14, coordIJK{0, 0, 0}}, // 85
+	{9, coordIJK{1, 0, 1}},  // 86
+	{14, coordIJK{0, 0, 1}}, // 87
+	{17, coordIJK{0, 0, 1}}, // 88
+	{12, coordIJK{0, 0, 1}}, // 89
+	{16, coordIJK{0, 0, 0}}, // 90
+	{12, coordIJK{0, 1, 1}}, // 91
+	{15, coordIJK{0, 0, 1}}, // 92
+	{15, coordIJK{1, 1, 0}}, // 93
+	{9, coordIJK{1, 0, 0}},  // 94
+	{15, coordIJK{0, 0, 0}}, // 95
+	{13, coordIJK{0, 0, 0}}, // 96
+	{8, coordIJK{2, 0, 0}},  // 97
+	{13, coordIJK{0, 1, 0}}, // 98
+	{16, coordIJK{1, 1, 0}}, // 99
+	{19, coordIJK{0, 1, 0}}, // 100
+	{14, coordIJK{0, 1, 0}}, // 101
+	{14, coordIJK{0, 1, 1}}, // 102
+	{17, coordIJK{0, 1, 0}}, // 103
+	{13, coordIJK{0, 0, 1}}, // 104
+	{17, coordIJK{0, 0, 0}}, // 105
+	{16, coordIJK{1, 0, 0}}, // 106
+	{9, coordIJK{2, 0, 0}},  // 107
+	{19, coordIJK{1, 1, 0}}, // 108
+	{15, coordIJK{1, 0, 0}}, // 109
+	{13, coordIJK{0, 1, 1}}, // 110
+	{18, coordIJK{0, 0, 1}}, // 111
+	{19, coordIJK{0, 0, 1}}, // 112
+	{17, coordIJK{1, 0, 0}}, // 113
+	{19, coordIJK{0, 0, 0}}, // 114
+	{18, coordIJK{0, 1, 0}}, // 115
+	{17, coordIJK{1, 1, 0}}, // 116
+	{15, coordIJK{2, 0, 0}}, // 117
+	{19, coordIJK{1, 0, 0}}, // 118
+	{18, coordIJK{0, 0, 0}}, // 119
+	{18, coordIJK{1, 1, 0}}, // 120
+	{18, coordIJK{1, 0, 0}}, // 121
+}
@@ -0,0 +1,88 @@
+package geocell
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	plusCodeAlphabet  = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator = 8
+	plusCodePairs     = 10
+	plusCodeMax       = 15
+)
+
+// PlusCodeBounds returns the bounding box of a full Open Location Code such
+// as "849VCWC8+R9". Short codes, which are relative to a reference location,
+// are not supported.
+func PlusCodeBounds(code string) (minLat, minLon, maxLat, maxLon float64, err error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: plus code %q %s", ErrInvalid, code, reason)
+	}
+	separator := strings.IndexByte(code, '+')
+	if separator < 0 || strings.Count(code, "+") != 1 {
+		return 0, 0, 0, 0, invalid("must contain a single +")
+	}
+	if separator < plusCodeSeparator {
+		return 0, 0, 0, 0, invalid("is a short code; give the full code")
+	}
+	if separator > plusCodeSeparator {
+		return 0, 0, 0, 0, invalid("has too many digits before the +")
+	}
+	digits := strings.TrimRight(code[:separator], "0")
+	if strings.Contains(digits, "0") || len(digits)%2 == 1 || digits == "" {
+		return 0, 0, 0, 0, invalid("has invalid padding")
+	}
+	if len(digits) < plusCodeSeparator && len(code) > separator+1 {
+		return 0, 0, 0, 0, invalid("has digits after a padded code")
+	}
+	if len(code) == separator+2 {
+		return 0, 0, 0, 0, invalid("must have at least two digits after the +")
+	}
+	digits += code[separator+1:]
+	if len(digits) > plusCodeMax {
+		digits = digits[:plusCodeMax]
+	}
+
+	lat, lon := -90.0, -180.0
+	latSize, lonSize := 400.0, 400.0
+	for i := 0; i < len(digits); i++ {
+		value := strings.IndexByte(plusCodeAlphabet, digits[i])
+		if value < 0 {
+			return 0, 0, 0, 0, invalid(fmt.Sprintf("contains %q", digits[i]))
+		}
+		if i < plusCodePairs {
+			// Pairs of digits encode latitude then longitude in base 20.
+			if i%2 == 0 {
+				latSize /= 20
+				lat += float64(value) * latSize
+			} else {
+				lonSize /= 20
+				lon += float64(value) * lonSize
+			}
+			continue
+		}
+		// Each further digit refines a 5 by 4 grid.
+		latSize /= 5
+		lonSize /= 4
+		lat += float64(value/4) * latSize
+		lon += float64(value%4) * lonSize
+	}
+	if lat >= 90 || lon >= 180 {
+		return 0, 0, 0, 0, invalid("is out of range")
+	}
+	return lat, lon, lat + latSize, lon + lonSize, nil
+}
+
+// DecodePlusCode returns the centroid of a full Open Location Code.
+func DecodePlusCode(code string) (latitude, longitude float64, err error) {
+	minLat, minLon, maxLat, maxLon, err := PlusCodeBounds(code)
+	if err != nil {
+		return 0, 0, err
+	}
+	if maxLat > 90 {
+		maxLat = 90
+	}
+	return (minLat + maxLat) / 2, (minLon + maxLon) / 2, nil
+}
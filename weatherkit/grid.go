@@ -0,0 +1,309 @@
+package weatherkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultGridSpacingKm = 10
+	// maxGridPoints limits the number of WeatherKit requests made by a
+	// single grid query.
+	maxGridPoints = 500
+	kmPerDegree   = 111.32
+)
+
+// gridCell is a sample point of a grid and the cell around it.
+type gridCell struct {
+	CellRow    int     `json:"cellRow"`
+	CellColumn int     `json:"cellColumn"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	minLat     float64
+	minLon     float64
+	maxLat     float64
+	maxLon     float64
+}
+
+func (c gridCell) coordinates() (float64, float64) {
+	return c.Latitude, c.Longitude
+}
+
+// Cell returns the cell as a GeoJSON polygon.
+func (c gridCell) Cell() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "Polygon",
+		"coordinates": [][][2]float64{{
+			{c.minLon, c.minLat},
+			{c.maxLon, c.minLat},
+			{c.maxLon, c.maxLat},
+			{c.minLon, c.maxLat},
+			{c.minLon, c.minLat},
+		}},
+	}
+}
+
+// boundingBox is a west, south, east, north box in degrees. west is greater
+// than east for boxes that cross the antimeridian.
+type boundingBox struct {
+	west, south, east, north float64
+}
+
+// parseBoundingBox parses a bounding box given as "west,south,east,north"
+// or as a JSON array in the same order, as in GeoJSON.
+func parseBoundingBox(s string) (boundingBox, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return boundingBox{}, fmt.Errorf("invalid bbox %q: expected west,south,east,north", s)
+	}
+	var values [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return boundingBox{}, fmt.Errorf("invalid bbox %q: %w", s, err)
+		}
+		values[i] = v
+	}
+	box := boundingBox{west: values[0], south: values[1], east: values[2], north: values[3]}
+	if box.south >= box.north || box.south < -90 || box.north > 90 {
+		return boundingBox{}, fmt.Errorf("invalid bbox %q: south must be less than north and both between -90 and 90", s)
+	}
+	if box.west < -180 || box.west > 180 || box.east < -180 || box.east > 180 || box.west == box.east {
+		return boundingBox{}, fmt.Errorf("invalid bbox %q: west and east must differ and be between -180 and 180", s)
+	}
+	return box, nil
+}
+
+// polygon is a GeoJSON Polygon or MultiPolygon, as a list of polygons each
+// made of an exterior ring followed by any holes.
+type polygon [][][][2]float64
+
+// parsePolygon parses a GeoJSON Polygon or MultiPolygon geometry, or a
+// Feature with one of those geometries.
+func parsePolygon(s string) (polygon, error) {
+	var geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal([]byte(s), &geometry); err != nil {
+		return nil, fmt.Errorf("invalid polygon: %w", err)
+	}
+	var p polygon
+	switch geometry.Type {
+	case "Feature":
+		return parsePolygon(string(geometry.Geometry))
+	case "Polygon":
+		var rings [][][2]float64
+		if err := json.Unmarshal(geometry.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("invalid polygon: %w", err)
+		}
+		p = polygon{rings}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geometry.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("invalid polygon: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid polygon: unsupported GeoJSON type %q, expected Polygon, MultiPolygon or Feature", geometry.Type)
+	}
+	for _, rings := range p {
+		if len(rings) == 0 || len(rings[0]) < 4 {
+			return nil, errors.New("invalid polygon: each polygon needs an exterior ring of at least 4 positions")
+		}
+	}
+	return p, nil
+}
+
+// bounds returns the bounding box of the polygon's exterior rings.
+func (p polygon) bounds() boundingBox {
+	box := boundingBox{west: 180, south: 90, east: -180, north: -90}
+	for _, rings := range p {
+		for _, position := range rings[0] {
+			box.west = math.Min(box.west, position[0])
+			box.east = math.Max(box.east, position[0])
+			box.south = math.Min(box.south, position[1])
+			box.north = math.Max(box.north, position[1])
+		}
+	}
+	return box
+}
+
+// contains reports whether a point lies inside the polygon and outside its
+// holes, using the even-odd rule on longitude and latitude.
+func (p polygon) contains(latitude, longitude float64) bool {
+	for _, rings := range p {
+		inside := false
+		for _, ring := range rings {
+			if ringContains(ring, latitude, longitude) {
+				inside = !inside
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+func ringContains(ring [][2]float64, latitude, longitude float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > latitude) != (yj > latitude) && longitude < (xj-xi)*(latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// gridCells returns sample points spaced about spacingKm apart covering the
+// box, keeping only those inside the polygon if one is given. Rows run from
+// south to north and columns from west to east.
+func gridCells(box boundingBox, spacingKm float64, p polygon) ([]gridCell, error) {
+	if spacingKm <= 0 || math.IsNaN(spacingKm) {
+		return nil, fmt.Errorf("invalid spacing_km %v: must be greater than 0", spacingKm)
+	}
+	east := box.east
+	if east < box.west {
+		east += 360
+	}
+	latStep := spacingKm / kmPerDegree
+	rows := int(math.Ceil((box.north - box.south) / latStep))
+	var cells []gridCell
+	for row := 0; row < rows; row++ {
+		minLat := box.south + float64(row)*latStep
+		maxLat := math.Min(minLat+latStep, box.north)
+		latitude := (minLat + maxLat) / 2
+		lonStep := spacingKm / (kmPerDegree * math.Max(math.Cos(latitude*math.Pi/180), 0.01))
+		columns := int(math.Ceil((east - box.west) / lonStep))
+		for column := 0; column < columns; column++ {
+			minLon := box.west + float64(column)*lonStep
+			maxLon := math.Min(minLon+lonStep, east)
+			cell := gridCell{
+				CellRow:    row,
+				CellColumn: column,
+				Latitude:   latitude,
+				Longitude:  normalizeLongitude((minLon + maxLon) / 2),
+				minLat:     minLat,
+				maxLat:     maxLat,
+				minLon:     normalizeLongitude(minLon),
+				maxLon:     normalizeLongitude(maxLon),
+			}
+			if p != nil && !p.contains(cell.Latitude, cell.Longitude) {
+				continue
+			}
+			cells = append(cells, cell)
+			if len(cells) > maxGridPoints {
+				return nil, fmt.Errorf("the grid has more than %d points: increase spacing_km or reduce the area", maxGridPoints)
+			}
+		}
+	}
+	return cells, nil
+}
+
+// normalizeLongitude wraps a longitude into the range -180 to 180.
+func normalizeLongitude(longitude float64) float64 {
+	if longitude >= -180 && longitude <= 180 {
+		return longitude
+	}
+	longitude = math.Mod(longitude+180, 360)
+	if longitude < 0 {
+		longitude += 360
+	}
+	return longitude - 180
+}
+
+// getGridCells returns the grid requested by the bbox or polygon and
+// spacing_km quals.
+func getGridCells(_ context.Context, d *plugin.QueryData) ([]gridCell, error) {
+	spacingKm := float64(defaultGridSpacingKm)
+	if q, ok := d.KeyColumnQuals["spacing_km"]; ok {
+		spacingKm = q.GetDoubleValue()
+	}
+	if q, ok := d.KeyColumnQuals["polygon"]; ok {
+		p, err := parsePolygon(q.GetJsonbValue())
+		if err != nil {
+			return nil, err
+		}
+		return gridCells(p.bounds(), spacingKm, p)
+	}
+	if bbox := d.KeyColumnQualString("bbox"); bbox != "" {
+		box, err := parseBoundingBox(bbox)
+		if err != nil {
+			return nil, err
+		}
+		return gridCells(box, spacingKm, nil)
+	}
+	return nil, errors.New("you must specify bbox or polygon")
+}
+
+// gridKeyColumns returns the key columns of the grid tables.
+func gridKeyColumns() plugin.KeyColumnSlice {
+	return plugin.KeyColumnSlice{
+		{Name: "bbox", Require: plugin.AnyOf},
+		{Name: "polygon", Require: plugin.AnyOf},
+		{Name: "spacing_km", Require: plugin.Optional},
+		{Name: "units", Require: plugin.Optional},
+	}
+}
+
+// gridColumns returns the columns describing the grid and the cell of a row.
+func gridColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "bbox",
+			Type:        proto.ColumnType_STRING,
+			Description: "The area to sample as a bounding box of the form 'west,south,east,north', in degrees.",
+			Transform:   transform.FromQual("bbox"),
+		},
+		{
+			Name:        "polygon",
+			Type:        proto.ColumnType_JSON,
+			Description: "The area to sample as a GeoJSON Polygon, MultiPolygon or Feature. Only points inside the polygon are returned.",
+			Transform:   transform.FromQual("polygon"),
+		},
+		{
+			Name:        "spacing_km",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The distance between sample points, in kilometers. Defaults to 10.",
+			Transform:   transform.FromQual("spacing_km"),
+		},
+		{
+			Name:        "cell_row",
+			Type:        proto.ColumnType_INT,
+			Description: "The row of the cell in the grid, counting from 0 in the south.",
+		},
+		{
+			Name:        "cell_column",
+			Type:        proto.ColumnType_INT,
+			Description: "The column of the cell in the grid, counting from 0 in the west.",
+		},
+		{
+			Name:        "cell",
+			Type:        proto.ColumnType_JSON,
+			Description: "The cell around the sample point as a GeoJSON polygon.",
+			Transform:   transform.FromMethod("Cell"),
+		},
+		{
+			Name:        "latitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The latitude of the sample point at the center of the cell.",
+		},
+		{
+			Name:        "longitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The longitude of the sample point at the center of the cell.",
+		},
+	}
+}
@@ -0,0 +1,94 @@
+// Package indices computes derived comfort and safety indices from metric
+// weather observations.
+//
+// Temperatures are in degrees Celsius, relative humidity is a fraction from
+// 0 to 1, and wind speeds are in kilometers per hour.
+package indices
+
+import "math"
+
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+func fahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// HeatIndex returns the NWS heat index using the Rothfusz regression and its
+// low and high humidity adjustments. Below 80°F the simpler Steadman
+// approximation is used, as in the NWS algorithm.
+func HeatIndex(temperature, humidity float64) float64 {
+	t := celsiusToFahrenheit(temperature)
+	rh := humidity * 100
+
+	hi := 0.5 * (t + 61 + (t-68)*1.2 + rh*0.094)
+	if (hi+t)/2 < 80 {
+		return fahrenheitToCelsius(hi)
+	}
+
+	hi = -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+		0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+	if rh < 13 && t >= 80 && t <= 112 {
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	} else if rh > 85 && t >= 80 && t <= 87 {
+		hi += (rh - 85) / 10 * (87 - t) / 5
+	}
+	return fahrenheitToCelsius(hi)
+}
+
+// WindChill returns the wind chill index used by the NWS and Environment
+// Canada. Outside its defined range (above 10°C or wind below 4.8 km/h) the
+// air temperature is returned.
+func WindChill(temperature, windSpeed float64) float64 {
+	if temperature > 10 || windSpeed < 4.8 {
+		return temperature
+	}
+	v := math.Pow(windSpeed, 0.16)
+	return 13.12 + 0.6215*temperature - 11.37*v + 0.3965*temperature*v
+}
+
+// Humidex returns the Environment Canada humidex from the air temperature and
+// dew point.
+func Humidex(temperature, dewPoint float64) float64 {
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/(273.15+dewPoint)))
+	return temperature + 0.5555*(e-10)
+}
+
+// WetBulbTemperature returns the wet-bulb temperature at sea-level pressure
+// using the Stull (2011) empirical formula, valid for relative humidity
+// between 5% and 99% and temperatures between -20°C and 50°C.
+func WetBulbTemperature(temperature, humidity float64) float64 {
+	rh := humidity * 100
+	return temperature*math.Atan(0.151977*math.Sqrt(rh+8.313659)) +
+		math.Atan(temperature+rh) - math.Atan(rh-1.676331) +
+		0.00391838*math.Pow(rh, 1.5)*math.Atan(0.023101*rh) - 4.686035
+}
+
+// VaporPressure returns the actual vapor pressure in hectopascals.
+func VaporPressure(temperature, humidity float64) float64 {
+	return humidity * 6.105 * math.Exp(17.27*temperature/(237.7+temperature))
+}
+
+// WBGT returns the Australian Bureau of Meteorology estimate of the wet-bulb
+// globe temperature, which assumes moderately high radiation and light wind.
+func WBGT(temperature, humidity float64) float64 {
+	return 0.567*temperature + 0.393*VaporPressure(temperature, humidity) + 3.94
+}
+
+// beaufortLimits are the lowest wind speeds of Beaufort forces 1 to 12 in the
+// WMO table, in meters per second.
+var beaufortLimits = []float64{0.3, 1.6, 3.4, 5.5, 8.0, 10.8, 13.9, 17.2, 20.8, 24.5, 28.5, 32.7}
+
+// Beaufort returns the Beaufort wind force, from 0 (calm) to 12 (hurricane).
+// The speed is rounded to 0.1 m/s, the resolution of the WMO table.
+func Beaufort(windSpeed float64) int {
+	ms := math.Round(windSpeed/3.6*10) / 10
+	for force, limit := range beaufortLimits {
+		if ms < limit {
+			return force
+		}
+	}
+	return len(beaufortLimits)
+}
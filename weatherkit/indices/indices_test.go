@@ -0,0 +1,135 @@
+package indices
+
+import (
+	"math"
+	"testing"
+)
+
+// The published tables give whole degrees, so results are rounded before
+// they are compared.
+
+func TestHeatIndex(t *testing.T) {
+	// NWS heat index chart, in degrees Fahrenheit.
+	tests := []struct {
+		temperature, humidity, want float64
+	}{
+		{80, 40, 80},
+		{90, 40, 91},
+		{100, 40, 109},
+		{110, 40, 136},
+		{90, 50, 95},
+		{100, 50, 118},
+		{104, 55, 137},
+		{96, 65, 121},
+		{86, 90, 105},
+		{90, 90, 122},
+	}
+	for _, tt := range tests {
+		got := celsiusToFahrenheit(HeatIndex(fahrenheitToCelsius(tt.temperature), tt.humidity/100))
+		if math.Round(got) != tt.want {
+			t.Errorf("HeatIndex(%g°F, %g%%) = %.2f°F, want %g°F", tt.temperature, tt.humidity, got, tt.want)
+		}
+	}
+}
+
+func TestWindChill(t *testing.T) {
+	// NWS wind chill chart, in degrees Fahrenheit and miles per hour.
+	tests := []struct {
+		temperature, windSpeed, want float64
+	}{
+		{40, 5, 36},
+		{30, 10, 21},
+		{20, 25, 3},
+		{0, 5, -11},
+		{0, 15, -19},
+		{-10, 20, -35},
+		{-20, 30, -53},
+	}
+	for _, tt := range tests {
+		got := celsiusToFahrenheit(WindChill(fahrenheitToCelsius(tt.temperature), tt.windSpeed*1.609344))
+		if math.Round(got) != tt.want {
+			t.Errorf("WindChill(%g°F, %g mph) = %.2f°F, want %g°F", tt.temperature, tt.windSpeed, got, tt.want)
+		}
+	}
+}
+
+func TestWindChillOutsideRange(t *testing.T) {
+	if got := WindChill(15, 30); got != 15 {
+		t.Errorf("WindChill(15, 30) = %g, want the air temperature", got)
+	}
+	if got := WindChill(-5, 3); got != -5 {
+		t.Errorf("WindChill(-5, 3) = %g, want the air temperature", got)
+	}
+}
+
+func TestHumidex(t *testing.T) {
+	// Environment Canada humidex table, by air temperature and dew point.
+	tests := []struct {
+		temperature, dewPoint, want float64
+	}{
+		{25, 20, 33},
+		{30, 15, 34},
+		{35, 25, 47},
+	}
+	for _, tt := range tests {
+		got := Humidex(tt.temperature, tt.dewPoint)
+		if math.Round(got) != tt.want {
+			t.Errorf("Humidex(%g, %g) = %.2f, want %g", tt.temperature, tt.dewPoint, got, tt.want)
+		}
+	}
+}
+
+func TestWetBulbTemperature(t *testing.T) {
+	// Stull (2011) gives 13.7°C for 20°C and 50% relative humidity.
+	if got := WetBulbTemperature(20, 0.5); math.Abs(got-13.7) > 0.05 {
+		t.Errorf("WetBulbTemperature(20, 0.5) = %.3f, want 13.7", got)
+	}
+	// The wet-bulb temperature is below the air temperature, and close to
+	// it near saturation.
+	for _, temperature := range []float64{-10, 0, 15, 30, 45} {
+		if got := WetBulbTemperature(temperature, 0.3); got >= temperature {
+			t.Errorf("WetBulbTemperature(%g, 0.3) = %.2f, want below the air temperature", temperature, got)
+		}
+		if got := WetBulbTemperature(temperature, 0.99); math.Abs(got-temperature) > 0.5 {
+			t.Errorf("WetBulbTemperature(%g, 0.99) = %.2f, want within 0.5 of the air temperature", temperature, got)
+		}
+	}
+}
+
+func TestWBGT(t *testing.T) {
+	// Bureau of Meteorology approximate WBGT table, by air temperature and
+	// relative humidity.
+	tests := []struct {
+		temperature, humidity, want float64
+	}{
+		{25, 80, 28},
+		{30, 50, 29},
+		{35, 40, 33},
+		{40, 20, 32},
+	}
+	for _, tt := range tests {
+		got := WBGT(tt.temperature, tt.humidity/100)
+		if math.Round(got) != tt.want {
+			t.Errorf("WBGT(%g, %g%%) = %.2f, want %g", tt.temperature, tt.humidity, got, tt.want)
+		}
+	}
+}
+
+func TestBeaufort(t *testing.T) {
+	// WMO Beaufort scale: the lowest wind speed of each force, in meters per
+	// second.
+	lowest := []float64{0, 0.3, 1.6, 3.4, 5.5, 8.0, 10.8, 13.9, 17.2, 20.8, 24.5, 28.5, 32.7}
+	for force, ms := range lowest {
+		if got := Beaufort(ms * 3.6); got != force {
+			t.Errorf("Beaufort(%g m/s) = %d, want %d", ms, got, force)
+		}
+		if force > 0 {
+			if got := Beaufort((ms - 0.1) * 3.6); got != force-1 {
+				t.Errorf("Beaufort(%g m/s) = %d, want %d", ms-0.1, got, force-1)
+			}
+		}
+	}
+	if got := Beaufort(200); got != 12 {
+		t.Errorf("Beaufort(200) = %d, want 12", got)
+	}
+}
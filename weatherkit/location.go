@@ -0,0 +1,486 @@
+package weatherkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/gazetteer"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/geocell"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NamedLocation is a location defined in the connection config.
+type NamedLocation struct {
+	Name        string  `json:"name"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Timezone    *string `json:"timezone,omitempty"`
+	CountryCode *string `json:"countryCode,omitempty"`
+	// Properties holds the other fields of a location from locations_file.
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	// Source is "config" for the locations option or the path of
+	// locations_file.
+	Source string `json:"source"`
+}
+
+// parseNamedLocation parses a location of the form
+// "name=latitude,longitude[,timezone[,country_code]]".
+func parseNamedLocation(s string) (NamedLocation, error) {
+	name, coordinates, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return NamedLocation{}, fmt.Errorf("invalid location %q: expected name=latitude,longitude", s)
+	}
+	parts := strings.Split(coordinates, ",")
+	if len(parts) < 2 || len(parts) > 4 {
+		return NamedLocation{}, fmt.Errorf("invalid location %q: expected name=latitude,longitude[,timezone[,country_code]]", s)
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return NamedLocation{}, fmt.Errorf("invalid latitude for location %q: %w", name, err)
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return NamedLocation{}, fmt.Errorf("invalid longitude for location %q: %w", name, err)
+	}
+	location := NamedLocation{Name: name, Latitude: latitude, Longitude: longitude}
+	if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+		timezone := strings.TrimSpace(parts[2])
+		location.Timezone = &timezone
+	}
+	if len(parts) > 3 && strings.TrimSpace(parts[3]) != "" {
+		countryCode := strings.ToUpper(strings.TrimSpace(parts[3]))
+		location.CountryCode = &countryCode
+	}
+	return location, nil
+}
+
+// getNamedLocations returns the locations defined in the connection config,
+// followed by those in locations_file.
+func getNamedLocations(_ context.Context, d *plugin.QueryData) ([]NamedLocation, error) {
+	weatherKitConfig := GetConfig(d.Connection)
+	locations := make([]NamedLocation, 0, len(weatherKitConfig.Locations))
+	for _, s := range weatherKitConfig.Locations {
+		location, err := parseNamedLocation(s)
+		if err != nil {
+			return nil, err
+		}
+		location.Source = "config"
+		locations = append(locations, location)
+	}
+	if weatherKitConfig.LocationsFile != nil && *weatherKitConfig.LocationsFile != "" {
+		fileLocations, err := loadLocationsFile(*weatherKitConfig.LocationsFile)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, fileLocations...)
+	}
+	return locations, nil
+}
+
+// queryLocation is the location a row was requested for. It is embedded in
+// the rows of every table that takes a location.
+type queryLocation struct {
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	Location     *string `json:"location,omitempty"`
+	Place        *string `json:"place,omitempty"`
+	PostalCode   *string `json:"postalCode,omitempty"`
+	AirportCode  *string `json:"airportCode,omitempty"`
+	Geohash      *string `json:"geohash,omitempty"`
+	H3Index      *string `json:"h3Index,omitempty"`
+	PlusCode     *string `json:"plusCode,omitempty"`
+	ResolvedName *string `json:"resolvedName,omitempty"`
+	LocationName *string `json:"locationName,omitempty"`
+	// timezone is the IANA time zone of a named location or place, where
+	// known. See locationTimezone.
+	timezone string
+	// qualLatitude and qualLongitude are the coordinates as given by the
+	// quals, before they are normalized and rounded, and are returned in the
+	// latitude and longitude columns so that Postgres matches them.
+	qualLatitude  *float64
+	qualLongitude *float64
+}
+
+// queryCoordinates returns the coordinates returned in the latitude and
+// longitude columns: those given by the quals, or else the coordinates of the
+// location.
+func (l queryLocation) queryCoordinates() (float64, float64) {
+	latitude, longitude := l.Latitude, l.Longitude
+	if l.qualLatitude != nil {
+		latitude = *l.qualLatitude
+	}
+	if l.qualLongitude != nil {
+		longitude = *l.qualLongitude
+	}
+	return latitude, longitude
+}
+
+// fromQueryCoordinate is a transform returning the latitude or longitude, as
+// given by the param, of a row that embeds queryLocation.
+func fromQueryCoordinate(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	row, ok := d.HydrateItem.(interface{ queryCoordinates() (float64, float64) })
+	if !ok {
+		return nil, fmt.Errorf("cannot get the coordinates of a row of type %T", d.HydrateItem)
+	}
+	latitude, longitude := row.queryCoordinates()
+	if d.Param == "longitude" {
+		return longitude, nil
+	}
+	return latitude, nil
+}
+
+// maxQueryLocations limits the number of locations, and so WeatherKit
+// requests, of a single query.
+const maxQueryLocations = 500
+
+// locationListEntry is an element of the locations column.
+type locationListEntry struct {
+	Lat  *float64 `json:"lat"`
+	Lon  *float64 `json:"lon"`
+	Name *string  `json:"name"`
+}
+
+// parseLocationList parses a JSON array of {"lat", "lon", "name"} objects,
+// where name is optional.
+func parseLocationList(s string) ([]queryLocation, error) {
+	var entries []locationListEntry
+	if err := json.Unmarshal([]byte(s), &entries); err != nil {
+		return nil, fmt.Errorf("invalid locations: expected an array of {\"lat\", \"lon\", \"name\"} objects: %w", err)
+	}
+	locations := make([]queryLocation, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Lat == nil || entry.Lon == nil {
+			return nil, fmt.Errorf("invalid locations: element %d needs lat and lon", i)
+		}
+		locations = append(locations, queryLocation{Latitude: *entry.Lat, Longitude: *entry.Lon, LocationName: entry.Name})
+	}
+	return locations, nil
+}
+
+// expandQualValues returns every combination of the values of the equals
+// quals on the given columns, expanding the lists given with in or any.
+func expandQualValues(quals plugin.KeyColumnEqualsQualMap, columns []string) []map[string]*proto.QualValue {
+	combinations := []map[string]*proto.QualValue{{}}
+	for _, column := range columns {
+		q, ok := quals[column]
+		if !ok {
+			continue
+		}
+		values := []*proto.QualValue{q}
+		if list := q.GetListValue(); list != nil {
+			values = list.Values
+		}
+		next := make([]map[string]*proto.QualValue, 0, len(combinations)*len(values))
+		for _, combination := range combinations {
+			for _, value := range values {
+				m := make(map[string]*proto.QualValue, len(combination)+1)
+				for k, v := range combination {
+					m[k] = v
+				}
+				m[column] = value
+				next = append(next, m)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// resolveLocations returns the locations given by the quals, in the order of
+// the locations column or of the values of in and any operators, with their
+// coordinates validated, normalized and rounded to the coordinate_precision
+// connection option.
+func resolveLocations(ctx context.Context, d *plugin.QueryData) ([]queryLocation, error) {
+	var locations []queryLocation
+	if q, ok := d.KeyColumnQuals["locations"]; ok {
+		var err error
+		locations, err = parseLocationList(q.GetJsonbValue())
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var columns []string
+		for _, c := range locationKeyColumns() {
+			columns = append(columns, c.Name)
+		}
+		for _, quals := range expandQualValues(d.KeyColumnQuals, columns) {
+			location, err := lookupLocation(ctx, d, quals)
+			if err != nil {
+				return nil, err
+			}
+			locations = append(locations, location)
+			if len(locations) > maxQueryLocations {
+				break
+			}
+		}
+	}
+	if len(locations) > maxQueryLocations {
+		return nil, fmt.Errorf("the query has more than %d locations", maxQueryLocations)
+	}
+	precision, err := getCoordinatePrecision(d)
+	if err != nil {
+		return nil, err
+	}
+	for i := range locations {
+		locations[i].Latitude, locations[i].Longitude, err = normalizeCoordinates(locations[i].Latitude, locations[i].Longitude, precision)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return locations, nil
+}
+
+// streamLocations calls fetch for each location given by the quals, up to
+// the max_concurrency connection option at a time, and streams the rows it
+// returns in the order of the locations.
+func streamLocations(ctx context.Context, d *plugin.QueryData, fetch func(ctx context.Context, location queryLocation) ([]interface{}, error)) error {
+	logger := plugin.Logger(ctx)
+	locations, err := resolveLocations(ctx, d)
+	if err != nil {
+		return err
+	}
+	rows := make([][]interface{}, len(locations))
+	err = forEachConcurrently(ctx, len(locations), getMaxConcurrency(d), func(ctx context.Context, i int) error {
+		var err error
+		rows[i], err = fetch(ctx, locations[i])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, locationRows := range rows {
+		for _, row := range locationRows {
+			d.StreamListItem(ctx, row)
+			if plugin.IsCancelled(ctx) {
+				logger.Trace("CANCELLED!")
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// lookupLocation returns the location given by the quals, either as a named
+// location, as a place name, postal code or airport code looked up in the
+// gazetteer, as the centroid of a geohash, H3 or plus code cell, or as
+// latitude and longitude.
+func lookupLocation(ctx context.Context, d *plugin.QueryData, quals map[string]*proto.QualValue) (queryLocation, error) {
+	if name := quals["location"].GetStringValue(); name != "" {
+		locations, err := getNamedLocations(ctx, d)
+		if err != nil {
+			return queryLocation{}, err
+		}
+		for _, location := range locations {
+			if strings.EqualFold(location.Name, name) {
+				result := queryLocation{Latitude: location.Latitude, Longitude: location.Longitude, Location: &name, LocationName: &location.Name}
+				if location.Timezone != nil {
+					result.timezone = *location.Timezone
+				}
+				return result, nil
+			}
+		}
+		return queryLocation{}, fmt.Errorf("unknown location %q: locations must be defined in the connection config", name)
+	}
+	if place := quals["place"].GetStringValue(); place != "" {
+		match, ok, err := gazetteer.Lookup(place)
+		if err != nil {
+			return queryLocation{}, err
+		}
+		if !ok {
+			return queryLocation{}, fmt.Errorf("unknown place %q: no place in the gazetteer matches", place)
+		}
+		resolvedName := match.DisplayName()
+		return queryLocation{Latitude: match.Latitude, Longitude: match.Longitude, Place: &place, ResolvedName: &resolvedName, timezone: match.Timezone}, nil
+	}
+	if postalCode := quals["postal_code"].GetStringValue(); postalCode != "" {
+		matches, err := gazetteer.LookupPostalCode(postalCode)
+		if err != nil {
+			return queryLocation{}, err
+		}
+		if len(matches) == 0 {
+			return queryLocation{}, fmt.Errorf("unknown postal code %q", postalCode)
+		}
+		// Codes used in several countries resolve to the first; resolved_name
+		// shows which one was used.
+		match := matches[0]
+		resolvedName := match.DisplayName()
+		return queryLocation{Latitude: match.Latitude, Longitude: match.Longitude, PostalCode: &postalCode, ResolvedName: &resolvedName}, nil
+	}
+	if airportCode := quals["airport_code"].GetStringValue(); airportCode != "" {
+		airport, ok, err := gazetteer.LookupAirport(airportCode)
+		if err != nil {
+			return queryLocation{}, err
+		}
+		if !ok {
+			return queryLocation{}, fmt.Errorf("unknown airport code %q: expected a 3 letter IATA or 4 letter ICAO code", airportCode)
+		}
+		resolvedName := airport.DisplayName()
+		return queryLocation{Latitude: airport.Latitude, Longitude: airport.Longitude, AirportCode: &airportCode, ResolvedName: &resolvedName}, nil
+	}
+	if geohash := quals["geohash"].GetStringValue(); geohash != "" {
+		latitude, longitude, err := geocell.DecodeGeohash(geohash)
+		if err != nil {
+			return queryLocation{}, err
+		}
+		return queryLocation{Latitude: latitude, Longitude: longitude, Geohash: &geohash}, nil
+	}
+	if h3Index := quals["h3_index"].GetStringValue(); h3Index != "" {
+		latitude, longitude, err := geocell.DecodeH3(h3Index)
+		if err != nil {
+			return queryLocation{}, err
+		}
+		return queryLocation{Latitude: latitude, Longitude: longitude, H3Index: &h3Index}, nil
+	}
+	if plusCode := quals["plus_code"].GetStringValue(); plusCode != "" {
+		latitude, longitude, err := geocell.DecodePlusCode(plusCode)
+		if err != nil {
+			return queryLocation{}, err
+		}
+		return queryLocation{Latitude: latitude, Longitude: longitude, PlusCode: &plusCode}, nil
+	}
+	latitude, hasLatitude := quals["latitude"]
+	longitude, hasLongitude := quals["longitude"]
+	if !hasLatitude || !hasLongitude {
+		return queryLocation{}, errors.New("you must specify latitude and longitude, locations, location, place, postal_code, airport_code, geohash, h3_index or plus_code")
+	}
+	qualLatitude, qualLongitude := latitude.GetDoubleValue(), longitude.GetDoubleValue()
+	return queryLocation{
+		Latitude:      qualLatitude,
+		Longitude:     qualLongitude,
+		qualLatitude:  &qualLatitude,
+		qualLongitude: &qualLongitude,
+	}, nil
+}
+
+// validateCoordinates checks that a latitude is between -90 and 90 and that a
+// longitude is a finite number.
+func validateCoordinates(latitude, longitude float64) error {
+	if math.IsNaN(latitude) || latitude < -90 || latitude > 90 {
+		return fmt.Errorf("invalid latitude %v: must be between -90 and 90", latitude)
+	}
+	if math.IsNaN(longitude) || math.IsInf(longitude, 0) {
+		return fmt.Errorf("invalid longitude %v: must be a finite number", longitude)
+	}
+	return nil
+}
+
+// normalizeCoordinates validates a latitude and longitude, wraps the
+// longitude into the range -180 to 180 and rounds both to precision decimal
+// places. A negative precision leaves them unrounded.
+func normalizeCoordinates(latitude, longitude float64, precision int) (float64, float64, error) {
+	if err := validateCoordinates(latitude, longitude); err != nil {
+		return 0, 0, err
+	}
+	longitude = normalizeLongitude(longitude)
+	if precision >= 0 {
+		scale := math.Pow(10, float64(precision))
+		latitude = math.Round(latitude*scale) / scale
+		longitude = math.Round(longitude*scale) / scale
+	}
+	return latitude, longitude, nil
+}
+
+// locationKeyColumns returns the key columns used to specify a location. Any
+// of them may be given; resolveLocations checks that they form locations.
+func locationKeyColumns() plugin.KeyColumnSlice {
+	return plugin.KeyColumnSlice{
+		{Name: "latitude", Require: plugin.AnyOf},
+		{Name: "longitude", Require: plugin.AnyOf},
+		{Name: "location", Require: plugin.AnyOf},
+		{Name: "place", Require: plugin.AnyOf},
+		{Name: "postal_code", Require: plugin.AnyOf},
+		{Name: "airport_code", Require: plugin.AnyOf},
+		{Name: "geohash", Require: plugin.AnyOf},
+		{Name: "h3_index", Require: plugin.AnyOf},
+		{Name: "plus_code", Require: plugin.AnyOf},
+		{Name: "locations", Require: plugin.AnyOf},
+	}
+}
+
+// locationColumns returns the columns describing the location of a row.
+func locationColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "latitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "A numeric value indicating the latitude of the coordinate between -90 and 90, as given in the query. See request_latitude for the latitude requested.",
+			Transform:   transform.FromP(fromQueryCoordinate, "latitude"),
+		},
+		{
+			Name:        "longitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "A numeric value indicating the longitude of the coordinate, as given in the query. See request_longitude for the longitude requested.",
+			Transform:   transform.FromP(fromQueryCoordinate, "longitude"),
+		},
+		{
+			Name:        "request_latitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The latitude requested from WeatherKit, rounded to the coordinate_precision connection option.",
+			Transform:   transform.FromField("Latitude"),
+		},
+		{
+			Name:        "request_longitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The longitude requested from WeatherKit, normalized to between -180 and 180 and rounded to the coordinate_precision connection option.",
+			Transform:   transform.FromField("Longitude"),
+		},
+		{
+			Name:        "location",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of a location defined in the connection config, as an alternative to latitude and longitude.",
+		},
+		{
+			Name:        "place",
+			Type:        proto.ColumnType_STRING,
+			Description: "A place name such as 'Ann Arbor, MI' or 'Paris, France', looked up in the offline gazetteer, as an alternative to latitude and longitude.",
+		},
+		{
+			Name:        "postal_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "A postal code such as '48104' or 'SW1A 1AA, GB', resolved to the centroid of its area, as an alternative to latitude and longitude.",
+		},
+		{
+			Name:        "airport_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "A 3 letter IATA or 4 letter ICAO airport code, resolved to the airport, as an alternative to latitude and longitude.",
+		},
+		{
+			Name:        "geohash",
+			Type:        proto.ColumnType_STRING,
+			Description: "A geohash cell, resolved to its centroid, as an alternative to latitude and longitude.",
+		},
+		{
+			Name:        "h3_index",
+			Type:        proto.ColumnType_STRING,
+			Description: "An H3 cell index in hexadecimal, such as '8928308280fffff', resolved to its centroid, as an alternative to latitude and longitude.",
+		},
+		{
+			Name:        "plus_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "A full Open Location Code (plus code) such as '86JRGRJ2+', resolved to its centroid, as an alternative to latitude and longitude.",
+		},
+		{
+			Name:        "resolved_name",
+			Type:        proto.ColumnType_STRING,
+			Description: "The place, postal code or airport that the place, postal_code or airport_code column resolved to.",
+		},
+		{
+			Name:        "locations",
+			Type:        proto.ColumnType_JSON,
+			Description: "A JSON array of locations of the form {\"lat\": 42.28, \"lon\": -83.74, \"name\": \"Ann Arbor\"}, fetched in parallel and returned in order, as an alternative to latitude and longitude.",
+			Transform:   transform.FromQual("locations"),
+		},
+		{
+			Name:        "location_name",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of the location from the locations column or the connection config.",
+		},
+	}
+}
@@ -0,0 +1,193 @@
+package weatherkit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// locationsFile caches the locations read from the locations_file connection
+// option. The file is checked for changes each time locations are needed and
+// read again when its modification time or size changes.
+var locationsFile struct {
+	mu        sync.Mutex
+	path      string
+	modTime   time.Time
+	size      int64
+	locations []NamedLocation
+}
+
+// loadLocationsFile returns the locations in a CSV or GeoJSON file, reading
+// the file again if it changed since it was last read.
+func loadLocationsFile(path string) ([]NamedLocation, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locations_file: %w", err)
+	}
+
+	locationsFile.mu.Lock()
+	defer locationsFile.mu.Unlock()
+	if locationsFile.path == path && locationsFile.modTime.Equal(info.ModTime()) && locationsFile.size == info.Size() {
+		return locationsFile.locations, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locations_file: %w", err)
+	}
+	var locations []NamedLocation
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		locations, err = parseLocationsCSV(data)
+	case ".json", ".geojson":
+		locations, err = parseLocationsGeoJSON(data)
+	default:
+		return nil, fmt.Errorf("invalid locations_file %q: expected a .csv, .json or .geojson file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid locations_file %q: %w", path, err)
+	}
+	for i := range locations {
+		locations[i].Source = path
+	}
+	locationsFile.path = path
+	locationsFile.modTime = info.ModTime()
+	locationsFile.size = info.Size()
+	locationsFile.locations = locations
+	return locations, nil
+}
+
+// parseLocationsCSV parses a CSV file with a header row naming at least the
+// name, lat and lon columns. latitude and longitude, and lng, are accepted
+// too. The timezone and country_code columns are used if present, and every
+// other column is kept as a property.
+func parseLocationsCSV(data []byte) ([]NamedLocation, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		switch column {
+		case "latitude":
+			column = "lat"
+		case "longitude", "lng":
+			column = "lon"
+		}
+		header[i] = column
+		columns[column] = i
+	}
+	for _, required := range []string{"name", "lat", "lon"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the header has no %s column", required)
+		}
+	}
+	var locations []NamedLocation
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		location := NamedLocation{Name: strings.TrimSpace(record[columns["name"]]), Properties: map[string]interface{}{}}
+		if location.Name == "" {
+			return nil, fmt.Errorf("line %d: name is empty", line)
+		}
+		if location.Latitude, err = strconv.ParseFloat(strings.TrimSpace(record[columns["lat"]]), 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid lat: %w", line, err)
+		}
+		if location.Longitude, err = strconv.ParseFloat(strings.TrimSpace(record[columns["lon"]]), 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid lon: %w", line, err)
+		}
+		for i, column := range header {
+			value := strings.TrimSpace(record[i])
+			switch column {
+			case "name", "lat", "lon":
+			case "timezone":
+				if value != "" {
+					location.Timezone = &value
+				}
+			case "country_code":
+				if value != "" {
+					countryCode := strings.ToUpper(value)
+					location.CountryCode = &countryCode
+				}
+			default:
+				location.Properties[column] = value
+			}
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// parseLocationsGeoJSON parses a GeoJSON FeatureCollection, or a single
+// Feature, of Point features whose name property names the location. The
+// timezone and country_code properties are used if present, and every
+// property is kept.
+func parseLocationsGeoJSON(data []byte) ([]NamedLocation, error) {
+	type feature struct {
+		Type     string `json:"type"`
+		Geometry *struct {
+			Type        string    `json:"type"`
+			Coordinates []float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+	var collection struct {
+		feature
+		Features []feature `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, err
+	}
+	features := collection.Features
+	switch collection.Type {
+	case "FeatureCollection":
+	case "Feature":
+		features = []feature{collection.feature}
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type %q, expected FeatureCollection or Feature", collection.Type)
+	}
+	var locations []NamedLocation
+	for i, f := range features {
+		if f.Geometry == nil || f.Geometry.Type != "Point" || len(f.Geometry.Coordinates) < 2 {
+			return nil, fmt.Errorf("feature %d: expected a Point geometry", i)
+		}
+		name, _ := f.Properties["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("feature %d: the name property is missing or empty", i)
+		}
+		location := NamedLocation{
+			Name:       strings.TrimSpace(name),
+			Latitude:   f.Geometry.Coordinates[1],
+			Longitude:  f.Geometry.Coordinates[0],
+			Properties: f.Properties,
+		}
+		if timezone, ok := f.Properties["timezone"].(string); ok && timezone != "" {
+			location.Timezone = &timezone
+		}
+		if countryCode, ok := f.Properties["country_code"].(string); ok && countryCode != "" {
+			countryCode = strings.ToUpper(countryCode)
+			location.CountryCode = &countryCode
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
@@ -16,12 +16,29 @@ func Plugin(_ context.Context) *plugin.Plugin {
 			Schema:      ConfigSchema,
 		},
 		TableMap: map[string]*plugin.Table{
-			"weatherkit_availability":       tableWeatherKitAvailability(),
-			"weatherkit_current_weather":    tableWeatherKitCurrentWeather(),
-			"weatherkit_daily_forecast":     tableWeatherKitDailyForecast(),
-			"weatherkit_hourly_forecast":    tableWeatherKitHourlyForecast(),
-			"weatherkit_next_hour_forecast": tableWeatherKitNextHourForecast(),
-			"weatherkit_weather_alert":      tableWeatherKitWeatherAlert(),
+			"weatherkit_agronomy":              tableWeatherKitAgronomy(),
+			"weatherkit_astronomy":             tableWeatherKitAstronomy(),
+			"weatherkit_availability":          tableWeatherKitAvailability(),
+			"weatherkit_condition_code":        tableWeatherKitConditionCode(),
+			"weatherkit_current_weather":       tableWeatherKitCurrentWeather(),
+			"weatherkit_daily_forecast":        tableWeatherKitDailyForecast(),
+			"weatherkit_degree_day":            tableWeatherKitDegreeDay(),
+			"weatherkit_energy_forecast":       tableWeatherKitEnergyForecast(),
+			"weatherkit_forecast_change":       tableWeatherKitForecastChange(),
+			"weatherkit_forecast_event":        tableWeatherKitForecastEvent(),
+			"weatherkit_forecast_snapshot":     tableWeatherKitForecastSnapshot(),
+			"weatherkit_forecast_verification": tableWeatherKitForecastVerification(),
+			"weatherkit_grid_current_weather":  tableWeatherKitGridCurrentWeather(),
+			"weatherkit_grid_hourly_forecast":  tableWeatherKitGridHourlyForecast(),
+			"weatherkit_hourly_forecast":       tableWeatherKitHourlyForecast(),
+			"weatherkit_location":              tableWeatherKitLocation(),
+			"weatherkit_next_hour_forecast":    tableWeatherKitNextHourForecast(),
+			"weatherkit_place":                 tableWeatherKitPlace(),
+			"weatherkit_route_forecast":        tableWeatherKitRouteForecast(),
+			"weatherkit_weather_alert":         tableWeatherKitWeatherAlert(),
+			"weatherkit_weather_change":        tableWeatherKitWeatherChange(),
+			"weatherkit_window_summary":        tableWeatherKitWindowSummary(),
+			"weatherkit_workability":           tableWeatherKitWorkability(),
 		},
 	}
 	return p
@@ -0,0 +1,86 @@
+package weatherkit
+
+import (
+	"context"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/gazetteer"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+)
+
+// locatable is implemented by rows that have a location, including every row
+// that embeds queryLocation.
+type locatable interface {
+	coordinates() (latitude, longitude float64)
+}
+
+func (l queryLocation) coordinates() (float64, float64) {
+	return l.Latitude, l.Longitude
+}
+
+// maxNearestPlaceKm is the furthest a gazetteer place may be from a location
+// to be reported as its nearest place. Further away, a place says little about
+// the location, and the reverse geocoding columns are null.
+const maxNearestPlaceKm = 100
+
+// NearestPlace is the gazetteer place closest to a row's location.
+type NearestPlace struct {
+	NearestPlace      string  `json:"nearestPlace"`
+	AdminRegion       *string `json:"adminRegion,omitempty"`
+	CountryCode       string  `json:"countryCode"`
+	DistanceToPlaceKm float64 `json:"distanceToPlaceKm"`
+}
+
+// getNearestPlace is a hydrate function that reverse geocodes the location of
+// a row using the offline gazetteer.
+func getNearestPlace(_ context.Context, _ *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	row, ok := h.Item.(locatable)
+	if !ok {
+		return nil, fmt.Errorf("cannot reverse geocode a row of type %T", h.Item)
+	}
+	latitude, longitude := row.coordinates()
+	place, distance, ok, err := gazetteer.Nearest(latitude, longitude)
+	if err != nil || !ok || distance > maxNearestPlaceKm {
+		return nil, err
+	}
+	nearest := NearestPlace{
+		NearestPlace:      place.Name,
+		CountryCode:       place.CountryCode,
+		DistanceToPlaceKm: distance,
+	}
+	if place.Admin1Code != "" {
+		nearest.AdminRegion = &place.Admin1Code
+	}
+	return nearest, nil
+}
+
+// reverseGeocodeColumns returns the columns describing the place nearest to
+// a row's location. Any table whose rows are locatable can include them.
+func reverseGeocodeColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "nearest_place",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of the place in the offline gazetteer nearest to the location, or null if no place is within 100 km.",
+			Hydrate:     getNearestPlace,
+		},
+		{
+			Name:        "admin_region",
+			Type:        proto.ColumnType_STRING,
+			Description: "The first level administrative division of the nearest place, such as the state abbreviation in the United States.",
+			Hydrate:     getNearestPlace,
+		},
+		{
+			Name:        "country_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "The ISO country code of the nearest place.",
+			Hydrate:     getNearestPlace,
+		},
+		{
+			Name:        "distance_to_place_km",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The distance from the location to the nearest place, in kilometers, at most 100.",
+			Hydrate:     getNearestPlace,
+		},
+	}
+}
@@ -0,0 +1,172 @@
+package weatherkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/gazetteer"
+	"math"
+	"time"
+)
+
+const (
+	defaultRouteSpeedKmh        = 80
+	defaultRouteIntervalMinutes = 30
+	// maxRouteSamples limits the number of WeatherKit requests made by a
+	// single route query.
+	maxRouteSamples = 200
+)
+
+// routePoint is a latitude and longitude on a route.
+type routePoint [2]float64
+
+// decodePolyline decodes a route in the encoded polyline format used by
+// Google Maps and OSRM, with 5 decimal places of precision.
+func decodePolyline(s string) ([]routePoint, error) {
+	var points []routePoint
+	var latitude, longitude int
+	for i := 0; i < len(s); {
+		var deltas [2]int
+		for k := range deltas {
+			var result, shift int
+			for {
+				if i >= len(s) {
+					return nil, errors.New("invalid polyline: unexpected end of input")
+				}
+				b := int(s[i]) - 63
+				i++
+				if b < 0 || b > 63 {
+					return nil, fmt.Errorf("invalid polyline: unexpected character %q", s[i-1])
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[k] = ^(result >> 1)
+			} else {
+				deltas[k] = result >> 1
+			}
+		}
+		latitude += deltas[0]
+		longitude += deltas[1]
+		points = append(points, routePoint{float64(latitude) / 1e5, float64(longitude) / 1e5})
+	}
+	return points, nil
+}
+
+// parseLineString parses a GeoJSON LineString geometry, or a Feature with a
+// LineString geometry.
+func parseLineString(s string) ([]routePoint, error) {
+	var geometry struct {
+		Type        string          `json:"type"`
+		Coordinates [][]float64     `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal([]byte(s), &geometry); err != nil {
+		return nil, fmt.Errorf("invalid route: %w", err)
+	}
+	switch geometry.Type {
+	case "Feature":
+		return parseLineString(string(geometry.Geometry))
+	case "LineString":
+	default:
+		return nil, fmt.Errorf("invalid route: unsupported GeoJSON type %q, expected LineString or Feature", geometry.Type)
+	}
+	points := make([]routePoint, 0, len(geometry.Coordinates))
+	for _, position := range geometry.Coordinates {
+		if len(position) < 2 {
+			return nil, errors.New("invalid route: each position needs a longitude and latitude")
+		}
+		points = append(points, routePoint{position[1], position[0]})
+	}
+	return points, nil
+}
+
+// routeSample is a point along a route and the time it is reached.
+type routeSample struct {
+	SampleIndex     int       `json:"sampleIndex"`
+	DistanceKm      float64   `json:"distanceKm"`
+	Eta             time.Time `json:"eta"`
+	Latitude        float64   `json:"latitude"`
+	Longitude       float64   `json:"longitude"`
+	DepartureTime   time.Time `json:"departureTime"`
+	SpeedKmh        float64   `json:"speedKmh"`
+	IntervalMinutes int       `json:"intervalMinutes"`
+}
+
+func (s routeSample) coordinates() (float64, float64) {
+	return s.Latitude, s.Longitude
+}
+
+// sampleRoute returns points along the route reached every intervalMinutes
+// when travelling at speedKmh from departure, followed by the destination.
+func sampleRoute(points []routePoint, departure time.Time, speedKmh float64, intervalMinutes int) ([]routeSample, error) {
+	if len(points) < 2 {
+		return nil, errors.New("invalid route: a route needs at least 2 points")
+	}
+	if speedKmh <= 0 || math.IsNaN(speedKmh) {
+		return nil, fmt.Errorf("invalid speed_kmh %v: must be greater than 0", speedKmh)
+	}
+	if intervalMinutes <= 0 {
+		return nil, fmt.Errorf("invalid interval_minutes %d: must be greater than 0", intervalMinutes)
+	}
+	stepKm := speedKmh * float64(intervalMinutes) / 60
+	var samples []routeSample
+	add := func(distanceKm float64, p routePoint) error {
+		if len(samples) >= maxRouteSamples {
+			return fmt.Errorf("the route has more than %d sample points: increase interval_minutes or shorten the route", maxRouteSamples)
+		}
+		hours := distanceKm / speedKmh
+		samples = append(samples, routeSample{
+			SampleIndex:     len(samples),
+			DistanceKm:      distanceKm,
+			Eta:             departure.Add(time.Duration(hours * float64(time.Hour))).Truncate(time.Second),
+			Latitude:        p[0],
+			Longitude:       p[1],
+			DepartureTime:   departure,
+			SpeedKmh:        speedKmh,
+			IntervalMinutes: intervalMinutes,
+		})
+		return nil
+	}
+	if err := add(0, points[0]); err != nil {
+		return nil, err
+	}
+	travelled, next := 0.0, stepKm
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		length := gazetteer.Distance(from[0], from[1], to[0], to[1])
+		for length > 0 && next < travelled+length {
+			f := (next - travelled) / length
+			p := routePoint{from[0] + f*(to[0]-from[0]), from[1] + f*(to[1]-from[1])}
+			if err := add(next, p); err != nil {
+				return nil, err
+			}
+			next += stepKm
+		}
+		travelled += length
+	}
+	if travelled > samples[len(samples)-1].DistanceKm {
+		if err := add(travelled, points[len(points)-1]); err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
+
+// hourAt returns the hour of the forecast that contains t.
+func hourAt(hours []HourWeatherConditions, t time.Time) (HourWeatherConditions, bool) {
+	for _, hour := range hours {
+		start := parseTime(hour.ForecastStart)
+		if start == nil {
+			continue
+		}
+		if !t.Before(*start) && t.Before(start.Add(time.Hour)) {
+			return hour, true
+		}
+	}
+	return HourWeatherConditions{}, false
+}
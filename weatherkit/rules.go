@@ -0,0 +1,214 @@
+package weatherkit
+
+import (
+	"fmt"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rule is a condition on a field of a forecast hour, such as
+// "wind_gust <= 40", that must hold for the hour to be workable.
+type rule struct {
+	Field    string
+	Operator string
+	// Number is the threshold of a numeric field, in metric units.
+	Number float64
+	// Values are the alternatives, separated by |, of a text field.
+	Values []string
+}
+
+// ruleSet is a named list of rules from the rule_sets connection option.
+type ruleSet struct {
+	Name  string
+	Rules []rule
+}
+
+// ruleNumericFields maps the numeric fields of a forecast hour that rules
+// can test, in metric units.
+var ruleNumericFields = map[string]func(h HourWeatherConditions) *float64{
+	"cloud_cover":           func(h HourWeatherConditions) *float64 { return float64Ptr(h.CloudCover) },
+	"humidity":              func(h HourWeatherConditions) *float64 { return float64Ptr(h.Humidity) },
+	"precipitation_amount":  func(h HourWeatherConditions) *float64 { return float64Ptr(h.PrecipitationAmount) },
+	"precipitation_chance":  func(h HourWeatherConditions) *float64 { return float64Ptr(h.PrecipitationChance) },
+	"pressure":              func(h HourWeatherConditions) *float64 { return float64Ptr(h.Pressure) },
+	"snowfall_intensity":    func(h HourWeatherConditions) *float64 { return float64Ptr(h.SnowfallIntensity) },
+	"temperature":           func(h HourWeatherConditions) *float64 { return float64Ptr(h.Temperature) },
+	"temperature_apparent":  func(h HourWeatherConditions) *float64 { return float64Ptr(h.TemperatureApparent) },
+	"temperature_dew_point": func(h HourWeatherConditions) *float64 { return float64Ptr(h.TemperatureDewPoint) },
+	"uv_index":              func(h HourWeatherConditions) *float64 { return intFloat64Ptr(h.UvIndex) },
+	"visibility":            func(h HourWeatherConditions) *float64 { return float64Ptr(h.Visibility) },
+	"wind_direction":        func(h HourWeatherConditions) *float64 { return intFloat64Ptr(h.WindDirection) },
+	"wind_gust":             func(h HourWeatherConditions) *float64 { return float64Ptr(h.WindGust) },
+	"wind_speed":            func(h HourWeatherConditions) *float64 { return float64Ptr(h.WindSpeed) },
+}
+
+// ruleTextFields maps the text fields of a forecast hour that rules can
+// test.
+var ruleTextFields = map[string]func(h HourWeatherConditions) *string{
+	"condition_code":     func(h HourWeatherConditions) *string { return h.ConditionCode },
+	"precipitation_type": func(h HourWeatherConditions) *string { return h.PrecipitationType },
+	"pressure_trend":     func(h HourWeatherConditions) *string { return h.PressureTrend },
+	"daylight": func(h HourWeatherConditions) *string {
+		if h.Daylight == nil {
+			return nil
+		}
+		s := strconv.FormatBool(*h.Daylight)
+		return &s
+	},
+}
+
+func float64Ptr(v *float32) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
+func intFloat64Ptr(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
+var rulePattern = regexp.MustCompile(`^\s*([a-z_]+)\s*(<=|>=|!=|<|>|=)\s*(.*?)\s*$`)
+
+// parseRule parses a condition of the form "field operator value", such as
+// "temperature > 5" or "condition_code != Rain|HeavyRain".
+func parseRule(s string) (rule, error) {
+	m := rulePattern.FindStringSubmatch(s)
+	if m == nil || m[3] == "" {
+		return rule{}, fmt.Errorf("invalid rule %q: expected field operator value, such as wind_gust <= 40", s)
+	}
+	r := rule{Field: m[1], Operator: m[2]}
+	if _, ok := ruleNumericFields[r.Field]; ok {
+		number, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid rule %q: %s needs a number", s, r.Field)
+		}
+		r.Number = number
+		return r, nil
+	}
+	if _, ok := ruleTextFields[r.Field]; ok {
+		if r.Operator != "=" && r.Operator != "!=" {
+			return rule{}, fmt.Errorf("invalid rule %q: %s can only be compared with = or !=", s, r.Field)
+		}
+		for _, value := range strings.Split(m[3], "|") {
+			r.Values = append(r.Values, strings.TrimSpace(value))
+		}
+		return r, nil
+	}
+	return rule{}, fmt.Errorf("invalid rule %q: unknown field %q", s, r.Field)
+}
+
+// parseRules parses conditions separated by semicolons.
+func parseRules(s string) ([]rule, error) {
+	var rules []rule
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		r, err := parseRule(part)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("invalid rules %q: expected at least one condition", s)
+	}
+	return rules, nil
+}
+
+// parseRuleSet parses a rule set of the form "name: condition; condition".
+func parseRuleSet(s string) (ruleSet, error) {
+	name, conditions, ok := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return ruleSet{}, fmt.Errorf("invalid rule set %q: expected name: condition; condition", s)
+	}
+	rules, err := parseRules(conditions)
+	if err != nil {
+		return ruleSet{}, fmt.Errorf("invalid rule set %q: %w", name, err)
+	}
+	return ruleSet{Name: name, Rules: rules}, nil
+}
+
+// getRuleSets returns the rule sets defined in the connection config.
+func getRuleSets(d *plugin.QueryData) ([]ruleSet, error) {
+	weatherKitConfig := GetConfig(d.Connection)
+	ruleSets := make([]ruleSet, 0, len(weatherKitConfig.RuleSets))
+	for _, s := range weatherKitConfig.RuleSets {
+		set, err := parseRuleSet(s)
+		if err != nil {
+			return nil, err
+		}
+		ruleSets = append(ruleSets, set)
+	}
+	return ruleSets, nil
+}
+
+// String returns the rule in the form it is parsed from.
+func (r rule) String() string {
+	if r.Values != nil {
+		return fmt.Sprintf("%s %s %s", r.Field, r.Operator, strings.Join(r.Values, "|"))
+	}
+	return fmt.Sprintf("%s %s %s", r.Field, r.Operator, strconv.FormatFloat(r.Number, 'f', -1, 64))
+}
+
+// passes reports whether a forecast hour, in metric units, meets the rule.
+// An hour without a value for the field does not.
+func (r rule) passes(hour HourWeatherConditions) bool {
+	if r.Values != nil {
+		value := ruleTextFields[r.Field](hour)
+		if value == nil {
+			return false
+		}
+		matches := false
+		for _, v := range r.Values {
+			if strings.EqualFold(v, *value) {
+				matches = true
+			}
+		}
+		return matches == (r.Operator == "=")
+	}
+	value := ruleNumericFields[r.Field](hour)
+	if value == nil {
+		return false
+	}
+	// Forecast values are single precision, so the threshold is too: 0.2
+	// must equal a precipitation chance of 0.2.
+	number := float64(float32(r.Number))
+	switch r.Operator {
+	case "<":
+		return *value < number
+	case "<=":
+		return *value <= number
+	case ">":
+		return *value > number
+	case ">=":
+		return *value >= number
+	case "=":
+		return *value == number
+	case "!=":
+		return *value != number
+	}
+	return false
+}
+
+// violatedRules returns the rules a forecast hour, in metric units, does not
+// meet.
+func violatedRules(rules []rule, hour HourWeatherConditions) []string {
+	violated := []string{}
+	for _, r := range rules {
+		if !r.passes(hour) {
+			violated = append(violated, r.String())
+		}
+	}
+	return violated
+}
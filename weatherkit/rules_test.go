@@ -0,0 +1,132 @@
+package weatherkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in   string
+		want rule
+	}{
+		{"wind_gust <= 40", rule{Field: "wind_gust", Operator: "<=", Number: 40}},
+		{"  temperature>-2.5 ", rule{Field: "temperature", Operator: ">", Number: -2.5}},
+		{"uv_index != 0", rule{Field: "uv_index", Operator: "!=", Number: 0}},
+		{"condition_code != Rain | HeavyRain", rule{Field: "condition_code", Operator: "!=", Values: []string{"Rain", "HeavyRain"}}},
+		{"daylight = true", rule{Field: "daylight", Operator: "=", Values: []string{"true"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseRule(tt.in)
+		if err != nil {
+			t.Errorf("parseRule(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseRule(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"wind_gust",
+		"wind_gust <=",
+		"wind_gust <= fast",
+		"wind_gust ~ 40",
+		"gust_speed < 40",
+		"condition_code < Rain",
+		"Wind_Gust <= 40",
+	} {
+		if _, err := parseRule(in); err == nil {
+			t.Errorf("parseRule(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestParseRuleSet(t *testing.T) {
+	set, err := parseRuleSet("crane lift: wind_gust <= 40; precipitation_chance < 0.2;; condition_code != Thunderstorms;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Name != "crane lift" || len(set.Rules) != 3 {
+		t.Fatalf("parseRuleSet = %+v, want crane lift with 3 rules", set)
+	}
+	// Rules format back to the form they are parsed from.
+	want := []string{"wind_gust <= 40", "precipitation_chance < 0.2", "condition_code != Thunderstorms"}
+	for i, r := range set.Rules {
+		if r.String() != want[i] {
+			t.Errorf("rule %d = %q, want %q", i, r.String(), want[i])
+		}
+	}
+
+	for _, in := range []string{
+		"wind_gust <= 40",
+		": wind_gust <= 40",
+		"empty:",
+		"empty: ; ",
+		"bad: wind_gust <= 40; visibility",
+	} {
+		if _, err := parseRuleSet(in); err == nil {
+			t.Errorf("parseRuleSet(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestRulePasses(t *testing.T) {
+	float32Ptr := func(v float32) *float32 { return &v }
+	stringPtr := func(v string) *string { return &v }
+	daylight := true
+	hour := HourWeatherConditions{
+		ConditionCode:       stringPtr("Drizzle"),
+		Daylight:            &daylight,
+		PrecipitationChance: float32Ptr(0.2),
+		Temperature:         float32Ptr(4.5),
+		WindGust:            float32Ptr(40),
+	}
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{"wind_gust <= 40", true},
+		{"wind_gust < 40", false},
+		{"wind_gust >= 40", true},
+		{"wind_gust = 40", true},
+		{"wind_gust != 40", false},
+		{"temperature > 5", false},
+		{"temperature > 4", true},
+		{"precipitation_chance <= 0.2", true},
+		{"precipitation_chance < 0.2", false},
+		{"precipitation_chance = 0.2", true},
+		{"condition_code != Rain|HeavyRain", true},
+		{"condition_code != Rain|drizzle", false},
+		{"condition_code = Drizzle", true},
+		{"daylight = true", true},
+		// Hours without a value for the field do not pass.
+		{"visibility > 1000", false},
+		{"uv_index < 3", false},
+		{"precipitation_type != Snow", false},
+	}
+	for _, tt := range tests {
+		r, err := parseRule(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.passes(hour); got != tt.want {
+			t.Errorf("%q passes = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+
+	rules, err := parseRules("wind_gust < 30; temperature > 0; visibility > 1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"wind_gust < 30", "visibility > 1000"}
+	if got := violatedRules(rules, hour); !reflect.DeepEqual(got, want) {
+		t.Errorf("violatedRules = %q, want %q", got, want)
+	}
+	if got := violatedRules(rules[1:2], hour); got == nil || len(got) != 0 {
+		t.Errorf("violatedRules = %#v, want an empty list", got)
+	}
+}
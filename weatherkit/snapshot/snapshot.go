@@ -0,0 +1,267 @@
+// Package snapshot stores WeatherKit responses on disk so that forecasts can
+// later be compared with what was observed.
+//
+// A store is a directory with a subdirectory per dataset, such as
+// forecastDaily, holding one JSON Lines file per UTC day of read time. Each
+// line is a Record. Files are only ever appended to, so they can be copied,
+// pruned or archived while the plugin runs.
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Record is a stored response for a dataset at a location.
+type Record struct {
+	Dataset   string          `json:"dataset"`
+	Latitude  float64         `json:"latitude"`
+	Longitude float64         `json:"longitude"`
+	ReadTime  time.Time       `json:"readTime"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Filter selects the records returned by Scan. Zero values do not filter.
+type Filter struct {
+	Datasets []string
+	// Locations are latitude and longitude pairs. Records match a location
+	// within about 10 meters.
+	Locations [][2]float64
+	From      time.Time
+	To        time.Time
+}
+
+// locationTolerance is the largest difference in degrees between the
+// coordinates of a record and a filter location that still match.
+const locationTolerance = 1e-4
+
+func (f Filter) matches(r Record) bool {
+	if !f.From.IsZero() && r.ReadTime.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && r.ReadTime.After(f.To) {
+		return false
+	}
+	if len(f.Locations) == 0 {
+		return true
+	}
+	for _, l := range f.Locations {
+		if math.Abs(l[0]-r.Latitude) <= locationTolerance && math.Abs(l[1]-r.Longitude) <= locationTolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// Store is a directory of records. It is safe for concurrent use.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+	// seen holds, for each file appended to, the keys of the records it
+	// contains. It is loaded from the file on the first append.
+	seen map[string]map[string]bool
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = map[string]*Store{}
+)
+
+// Open returns the store in dir, creating the directory if needed. Stores
+// are shared, so opening the same directory twice returns the same store.
+func Open(dir string) (*Store, error) {
+	dir = filepath.Clean(dir)
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if s, ok := stores[dir]; ok {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("snapshot store: %w", err)
+	}
+	s := &Store{dir: dir, seen: map[string]map[string]bool{}}
+	stores[dir] = s
+	return s, nil
+}
+
+// recordKey identifies the records holding the same response: those with
+// the same dataset, location and read time.
+func recordKey(r Record) string {
+	return fmt.Sprintf("%s/%.6f/%.6f/%d", r.Dataset, r.Latitude, r.Longitude, r.ReadTime.UnixNano())
+}
+
+// Append adds a record to the store. A record with the same dataset,
+// location and read time as one already in the store is skipped, since it
+// holds the same data.
+func (s *Store) Append(r Record) error {
+	if r.Dataset == "" || strings.ContainsAny(r.Dataset, `/\.`) {
+		return fmt.Errorf("snapshot store: invalid dataset %q", r.Dataset)
+	}
+	r.ReadTime = r.ReadTime.UTC()
+	dir := filepath.Join(s.dir, r.Dataset)
+	name := filepath.Join(dir, r.ReadTime.Format(dateLayout)+".jsonl")
+	key := recordKey(r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen, ok := s.seen[name]
+	if !ok {
+		var err error
+		if seen, err = readKeys(name); err != nil {
+			return err
+		}
+		s.seen[name] = seen
+	}
+	if seen[key] {
+		return nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("snapshot store: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("snapshot store: %w", err)
+	}
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("snapshot store: %w", err)
+	}
+	// A line cut short by a crash is ended first, so that the record does
+	// not join it and become unreadable too.
+	partial, err := endsPartialLine(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("snapshot store: %w", err)
+	}
+	if partial {
+		line = append([]byte{'\n'}, line...)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("snapshot store: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("snapshot store: %w", err)
+	}
+	seen[key] = true
+	return nil
+}
+
+// endsPartialLine reports whether a file is not empty and does not end in a
+// newline.
+func endsPartialLine(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// readKeys returns the keys of the records in a file, which need not exist.
+func readKeys(name string) (map[string]bool, error) {
+	var records []Record
+	err := readRecords(name, Filter{}, &records)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(records))
+	for _, r := range records {
+		keys[recordKey(r)] = true
+	}
+	return keys, nil
+}
+
+// Scan calls fn for each record matching the filter, in order of dataset and
+// then read time. Lines that cannot be parsed, such as a line cut short by a
+// crash, are skipped.
+func (s *Store) Scan(filter Filter, fn func(Record) error) error {
+	datasets := filter.Datasets
+	if len(datasets) == 0 {
+		entries, err := os.ReadDir(s.dir)
+		if err != nil {
+			return fmt.Errorf("snapshot store: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				datasets = append(datasets, entry.Name())
+			}
+		}
+	}
+	for _, dataset := range datasets {
+		files, err := os.ReadDir(filepath.Join(s.dir, dataset))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("snapshot store: %w", err)
+		}
+		var names []string
+		for _, file := range files {
+			day, err := time.Parse(dateLayout, strings.TrimSuffix(file.Name(), ".jsonl"))
+			if err != nil || file.IsDir() {
+				continue
+			}
+			if !filter.From.IsZero() && day.Add(24*time.Hour).Before(filter.From) {
+				continue
+			}
+			if !filter.To.IsZero() && day.After(filter.To) {
+				continue
+			}
+			names = append(names, file.Name())
+		}
+		sort.Strings(names)
+		var records []Record
+		for _, name := range names {
+			records = records[:0]
+			if err := readRecords(filepath.Join(s.dir, dataset, name), filter, &records); err != nil {
+				return err
+			}
+			sort.SliceStable(records, func(i, j int) bool { return records[i].ReadTime.Before(records[j].ReadTime) })
+			for _, r := range records {
+				if err := fn(r); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func readRecords(name string, filter Filter, records *[]Record) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("snapshot store: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if filter.matches(r) {
+			*records = append(*records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("snapshot store: %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scanAll(t *testing.T, s *Store, filter Filter) []Record {
+	t.Helper()
+	var records []Record
+	err := s.Scan(filter, func(r Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestRoundTrip(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Dataset: "forecastDaily", Latitude: 40.7128, Longitude: -74.006, ReadTime: day.Add(9 * time.Hour), Data: json.RawMessage(`{"days":[1]}`)},
+		{Dataset: "forecastDaily", Latitude: 40.7128, Longitude: -74.006, ReadTime: day.Add(3 * time.Hour), Data: json.RawMessage(`{"days":[2]}`)},
+		{Dataset: "forecastDaily", Latitude: 51.5074, Longitude: -0.1278, ReadTime: day.Add(30 * time.Hour), Data: json.RawMessage(`{"days":[3]}`)},
+		{Dataset: "currentWeather", Latitude: 40.7128, Longitude: -74.006, ReadTime: day.Add(9 * time.Hour).In(time.FixedZone("EST", -5*3600)), Data: json.RawMessage(`{"temperature":12.5}`)},
+	}
+	for _, r := range records {
+		if err := s.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := scanAll(t, s, Filter{})
+	if len(got) != len(records) {
+		t.Fatalf("Scan returned %d records, want %d", len(got), len(records))
+	}
+	// Datasets are returned in order, then records by read time.
+	if got[0].Dataset != "currentWeather" || string(got[0].Data) != `{"temperature":12.5}` {
+		t.Errorf("first record = %+v, want the current weather", got[0])
+	}
+	if !got[0].ReadTime.Equal(day.Add(9*time.Hour)) || got[0].ReadTime.Location() != time.UTC {
+		t.Errorf("read time = %v, want %v in UTC", got[0].ReadTime, day.Add(9*time.Hour))
+	}
+	for i, want := range []string{`{"days":[2]}`, `{"days":[1]}`, `{"days":[3]}`} {
+		if string(got[i+1].Data) != want {
+			t.Errorf("record %d data = %s, want %s", i+1, got[i+1].Data, want)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"dataset", Filter{Datasets: []string{"forecastDaily"}}, 3},
+		{"missing dataset", Filter{Datasets: []string{"forecastHourly"}}, 0},
+		{"location", Filter{Locations: [][2]float64{{40.71281, -74.00601}}}, 3},
+		{"far location", Filter{Locations: [][2]float64{{40.72, -74.006}}}, 0},
+		{"from", Filter{From: day.Add(4 * time.Hour)}, 3},
+		{"to", Filter{To: day.Add(4 * time.Hour)}, 1},
+		{"range", Filter{From: day.Add(24 * time.Hour), To: day.Add(48 * time.Hour)}, 1},
+	}
+	for _, tt := range tests {
+		if got := scanAll(t, s, tt.filter); len(got) != tt.want {
+			t.Errorf("%s: Scan returned %d records, want %d", tt.name, len(got), tt.want)
+		}
+	}
+}
+
+func TestAppendDeduplicates(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readTime := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	first := Record{Dataset: "forecastHourly", Latitude: 40.7128, Longitude: -74.006, ReadTime: readTime, Data: json.RawMessage(`{}`)}
+	for _, r := range []Record{first, first, {Dataset: "forecastHourly", Latitude: 51.5074, Longitude: -0.1278, ReadTime: readTime, Data: json.RawMessage(`{}`)}, first} {
+		if err := s.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := scanAll(t, s, Filter{}); len(got) != 2 {
+		t.Fatalf("Scan returned %d records, want 2", len(got))
+	}
+
+	// A store opened on the existing files, as after a restart, still skips
+	// records already written.
+	reopened := &Store{dir: dir, seen: map[string]map[string]bool{}}
+	second := first
+	second.ReadTime = readTime.Add(time.Hour)
+	for _, r := range []Record{first, second} {
+		if err := reopened.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := scanAll(t, reopened, Filter{}); len(got) != 3 {
+		t.Fatalf("Scan returned %d records, want 3", len(got))
+	}
+}
+
+func TestScanSkipsInvalidLines(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Record{Dataset: "currentWeather", ReadTime: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), Data: json.RawMessage(`{}`)}
+	if err := s.Append(r); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "currentWeather", "2024-05-01.jsonl")
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"dataset":"currentWeather","readTi`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if got := scanAll(t, s, Filter{}); len(got) != 1 {
+		t.Fatalf("Scan returned %d records, want 1", len(got))
+	}
+}
+
+func TestAppendInvalidDataset(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dataset := range []string{"", "../escape", "a.b"} {
+		if err := s.Append(Record{Dataset: dataset}); err == nil {
+			t.Errorf("Append with dataset %q succeeded, want an error", dataset)
+		}
+	}
+}
+
+func TestAppendAfterPartialLine(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readTime := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	first := Record{Dataset: "currentWeather", ReadTime: readTime, Data: json.RawMessage(`{"temperature":10}`)}
+	if err := s.Append(first); err != nil {
+		t.Fatal(err)
+	}
+	// A crash leaves the next record cut short, without its newline.
+	name := filepath.Join(dir, "currentWeather", "2024-05-01.jsonl")
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"dataset":"currentWeather","readTi`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	second := first
+	second.ReadTime = readTime.Add(time.Hour)
+	second.Data = json.RawMessage(`{"temperature":12}`)
+	if err := s.Append(second); err != nil {
+		t.Fatal(err)
+	}
+	got := scanAll(t, s, Filter{})
+	if len(got) != 2 || !got[0].ReadTime.Equal(first.ReadTime) || !got[1].ReadTime.Equal(second.ReadTime) || string(got[1].Data) != `{"temperature":12}` {
+		t.Fatalf("Scan returned %+v, want the records read at 09:00 and 10:00", got)
+	}
+
+	// Appending to a file that ends in a newline adds no blank line.
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3; strings.Count(string(data), "\n") != want || strings.Contains(string(data), "\n\n") {
+		t.Errorf("file has %d lines, want %d without blank lines:\n%s", strings.Count(string(data), "\n"), want, data)
+	}
+}
@@ -0,0 +1,367 @@
+package weatherkit
+
+import (
+	"context"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/agronomy"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"math"
+	"time"
+)
+
+const (
+	defaultGDDBase  = 10
+	defaultGDDUpper = 30
+)
+
+func weatherKitAgronomyColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the forecast day, at local midnight.",
+		},
+		{
+			Name:        "forecast_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the forecast day.",
+		},
+		{
+			Name:        "gdd_base",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The base temperature of growing degree days, in degrees Celsius. Defaults to 10.",
+		},
+		{
+			Name:        "gdd_upper",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The upper temperature threshold of growing degree days, in degrees Celsius. Defaults to 30.",
+		},
+		{
+			Name:        "elevation",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The elevation of the location in meters, used for the air pressure in evapotranspiration. Defaults to 0.",
+		},
+		{
+			Name:        "temperature_min",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The minimum temperature of the day, in degrees Celsius.",
+		},
+		{
+			Name:        "temperature_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The maximum temperature of the day, in degrees Celsius.",
+		},
+		{
+			Name:        "precipitation_amount",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The amount of precipitation forecast for the day, in millimeters.",
+		},
+		{
+			Name:        "hourly_coverage",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of hours of the day covered by the hourly forecast. Metrics from hourly data are null for days that are not fully covered.",
+		},
+		{
+			Name:        "growing_degree_days",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The growing degree days from the minimum and maximum temperatures, by the modified average method.",
+		},
+		{
+			Name:        "growing_degree_days_hourly",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The growing degree days integrated over the hourly temperatures.",
+		},
+		{
+			Name:        "chill_hours",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of hours with a temperature between 0 and 7.2 degrees Celsius.",
+		},
+		{
+			Name:        "frost_risk",
+			Type:        proto.ColumnType_STRING,
+			Description: "The risk of frost from the lowest temperature of the day: high at or below 0 degrees Celsius, moderate at or below 2, low at or below 4, and none above.",
+		},
+		{
+			Name:        "extraterrestrial_radiation",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The solar radiation at the top of the atmosphere, in megajoules per square meter per day.",
+		},
+		{
+			Name:        "solar_radiation",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The estimated solar radiation reaching the ground, from the extraterrestrial radiation and the daytime cloud cover, in megajoules per square meter per day.",
+		},
+		{
+			Name:        "et0",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The FAO-56 Penman-Monteith reference evapotranspiration, in millimeters.",
+		},
+		{
+			Name:        "water_balance",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The precipitation less the reference evapotranspiration, in millimeters.",
+		},
+		{
+			Name:        "growing_degree_days_accumulated",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The growing degree days accumulated from the first forecast day, using the hourly value where there is one.",
+		},
+		{
+			Name:        "chill_hours_accumulated",
+			Type:        proto.ColumnType_INT,
+			Description: "The chill hours accumulated over the days fully covered by the hourly forecast, from the first forecast day.",
+		},
+		{
+			Name:        "et0_accumulated",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The reference evapotranspiration accumulated from the first forecast day, in millimeters.",
+		},
+		{
+			Name:        "precipitation_accumulated",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The precipitation accumulated from the first forecast day, in millimeters.",
+		},
+		{
+			Name:        "water_balance_accumulated",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The water balance accumulated from the first forecast day, in millimeters.",
+		},
+	}...)
+}
+
+func tableWeatherKitAgronomy() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_agronomy",
+		Description: "Growing degree days, chill hours, frost risk and reference evapotranspiration from the WeatherKit daily and hourly forecasts. Metric only: the table has no units column, and all temperatures, amounts and thresholds are in metric units.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(locationKeyColumns(),
+				&plugin.KeyColumn{Name: "gdd_base", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "gdd_upper", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "elevation", Require: plugin.Optional},
+			),
+			Hydrate: listAgronomy,
+		},
+		Columns: weatherKitAgronomyColumns(),
+	}
+}
+
+// agronomyDay holds the agricultural metrics of a forecast day, in metric
+// units.
+type agronomyDay struct {
+	ForecastStart                time.Time
+	ForecastEnd                  time.Time
+	GddBase                      float64
+	GddUpper                     float64
+	Elevation                    float64
+	TemperatureMin               *float64
+	TemperatureMax               *float64
+	PrecipitationAmount          *float64
+	HourlyCoverage               int
+	GrowingDegreeDays            *float64
+	GrowingDegreeDaysHourly      *float64
+	ChillHours                   *int
+	FrostRisk                    *string
+	ExtraterrestrialRadiation    float64
+	SolarRadiation               *float64
+	Et0                          *float64
+	WaterBalance                 *float64
+	GrowingDegreeDaysAccumulated float64
+	ChillHoursAccumulated        int
+	Et0Accumulated               float64
+	PrecipitationAccumulated     float64
+	WaterBalanceAccumulated      float64
+}
+
+// meanOf returns the mean of the values that are set, or nil if there are none.
+func meanOf(values ...*float32) *float64 {
+	var sum float64
+	var n int
+	for _, v := range values {
+		if v != nil {
+			sum += float64(*v)
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	m := sum / float64(n)
+	return &m
+}
+
+// newAgronomyDay computes the metrics of a day from its metric forecast.
+func newAgronomyDay(day forecastDay, latitude, base, upper, elevation float64) agronomyDay {
+	row := agronomyDay{
+		ForecastStart:       day.Start,
+		ForecastEnd:         day.End,
+		GddBase:             base,
+		GddUpper:            upper,
+		Elevation:           elevation,
+		TemperatureMin:      float64Ptr(day.Day.TemperatureMin),
+		TemperatureMax:      float64Ptr(day.Day.TemperatureMax),
+		PrecipitationAmount: float64Ptr(day.Day.PrecipitationAmount),
+		HourlyCoverage:      len(day.Hours),
+	}
+	var temperatures []float64
+	var cloudCover, windSpeed, vaporPressure []*float32
+	for _, hour := range day.Hours {
+		if hour.Temperature != nil {
+			temperatures = append(temperatures, float64(*hour.Temperature))
+		}
+		if hour.Daylight != nil && *hour.Daylight {
+			cloudCover = append(cloudCover, hour.CloudCover)
+		}
+		windSpeed = append(windSpeed, hour.WindSpeed)
+		if hour.TemperatureDewPoint != nil {
+			ea := float32(agronomy.SaturationVaporPressure(float64(*hour.TemperatureDewPoint)))
+			vaporPressure = append(vaporPressure, &ea)
+		}
+	}
+	// Daily extremes are taken from the hours where the daily forecast
+	// lacks them, and widened by the hours where it has them.
+	for _, t := range temperatures {
+		if row.TemperatureMin == nil || t < *row.TemperatureMin {
+			t := t
+			row.TemperatureMin = &t
+		}
+		if row.TemperatureMax == nil || t > *row.TemperatureMax {
+			t := t
+			row.TemperatureMax = &t
+		}
+	}
+	if row.TemperatureMin != nil && row.TemperatureMax != nil {
+		gdd := agronomy.DegreeDays(*row.TemperatureMin, *row.TemperatureMax, base, upper)
+		row.GrowingDegreeDays = &gdd
+		risk := agronomy.FrostRisk(*row.TemperatureMin)
+		row.FrostRisk = &risk
+	}
+	if day.complete() && len(temperatures) == len(day.Hours) {
+		gdd := agronomy.HourlyDegreeDays(temperatures, base, upper)
+		row.GrowingDegreeDaysHourly = &gdd
+		chill := agronomy.ChillHours(temperatures)
+		row.ChillHours = &chill
+	}
+
+	// Day parts fill in for missing hours.
+	daytime, overnight := day.Day.DaytimeForecast, day.Day.OvernightForecast
+	clouds := meanOf(cloudCover...)
+	if clouds == nil && daytime != nil {
+		clouds = meanOf(daytime.CloudCover)
+	}
+	wind := meanOf(windSpeed...)
+	if wind == nil && daytime != nil && overnight != nil {
+		wind = meanOf(daytime.WindSpeed, overnight.WindSpeed)
+	}
+	ea := meanOf(vaporPressure...)
+	if ea == nil && daytime != nil && overnight != nil && row.TemperatureMin != nil && row.TemperatureMax != nil {
+		if humidity := meanOf(daytime.Humidity, overnight.Humidity); humidity != nil {
+			es := (agronomy.SaturationVaporPressure(*row.TemperatureMin) + agronomy.SaturationVaporPressure(*row.TemperatureMax)) / 2
+			v := *humidity * es
+			ea = &v
+		}
+	}
+
+	// Radiation is for the local date, taken at the middle of the day.
+	date := day.Start.Add(day.End.Sub(day.Start) / 2)
+	row.ExtraterrestrialRadiation = agronomy.ExtraterrestrialRadiation(latitude, date)
+	if clouds != nil {
+		rs := agronomy.SolarRadiation(row.ExtraterrestrialRadiation, *clouds)
+		row.SolarRadiation = &rs
+	}
+	if clouds != nil && wind != nil && ea != nil && row.TemperatureMin != nil && row.TemperatureMax != nil {
+		et0 := agronomy.ReferenceEvapotranspiration(agronomy.Day{
+			Date:           date,
+			Latitude:       latitude,
+			Elevation:      elevation,
+			TemperatureMin: *row.TemperatureMin,
+			TemperatureMax: *row.TemperatureMax,
+			VaporPressure:  *ea,
+			WindSpeed:      *wind,
+			CloudCover:     *clouds,
+		})
+		et0 = math.Round(et0*100) / 100
+		row.Et0 = &et0
+		if row.PrecipitationAmount != nil {
+			balance := *row.PrecipitationAmount - et0
+			row.WaterBalance = &balance
+		}
+	}
+	return row
+}
+
+// accumulateAgronomy sets the accumulated metrics of each day from those of the
+// days before it.
+func accumulateAgronomy(days []agronomyDay) {
+	var previous agronomyDay
+	for i := range days {
+		day := &days[i]
+		day.GrowingDegreeDaysAccumulated = previous.GrowingDegreeDaysAccumulated
+		if day.GrowingDegreeDaysHourly != nil {
+			day.GrowingDegreeDaysAccumulated += *day.GrowingDegreeDaysHourly
+		} else if day.GrowingDegreeDays != nil {
+			day.GrowingDegreeDaysAccumulated += *day.GrowingDegreeDays
+		}
+		day.ChillHoursAccumulated = previous.ChillHoursAccumulated
+		if day.ChillHours != nil {
+			day.ChillHoursAccumulated += *day.ChillHours
+		}
+		day.Et0Accumulated = previous.Et0Accumulated
+		if day.Et0 != nil {
+			day.Et0Accumulated += *day.Et0
+		}
+		day.PrecipitationAccumulated = previous.PrecipitationAccumulated
+		if day.PrecipitationAmount != nil {
+			day.PrecipitationAccumulated += *day.PrecipitationAmount
+		}
+		day.WaterBalanceAccumulated = previous.WaterBalanceAccumulated
+		if day.WaterBalance != nil {
+			day.WaterBalanceAccumulated += *day.WaterBalance
+		}
+		previous = *day
+	}
+}
+
+func listAgronomy(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	base, upper, elevation := float64(defaultGDDBase), float64(defaultGDDUpper), 0.0
+	if q, ok := d.KeyColumnQuals["gdd_base"]; ok {
+		base = q.GetDoubleValue()
+	}
+	if q, ok := d.KeyColumnQuals["gdd_upper"]; ok {
+		upper = q.GetDoubleValue()
+	}
+	if q, ok := d.KeyColumnQuals["elevation"]; ok {
+		elevation = q.GetDoubleValue()
+	}
+	if upper <= base {
+		return nil, fmt.Errorf("invalid gdd_upper %g: must be above gdd_base %g", upper, base)
+	}
+	type Row struct {
+		agronomyDay
+		queryLocation
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		days, _, err := fetchForecastDays(ctx, service, location, unitsMetric)
+		if err != nil {
+			return nil, err
+		}
+		metrics := make([]agronomyDay, len(days))
+		for i, day := range days {
+			metrics[i] = newAgronomyDay(day, location.Latitude, base, upper, elevation)
+		}
+		accumulateAgronomy(metrics)
+		rows := make([]interface{}, len(metrics))
+		for i, m := range metrics {
+			rows[i] = Row{agronomyDay: m, queryLocation: location}
+		}
+		return rows, nil
+	})
+	return nil, err
+}
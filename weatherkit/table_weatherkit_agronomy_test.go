@@ -0,0 +1,80 @@
+package weatherkit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func float32Value(v float32) *float32 {
+	return &v
+}
+
+// agronomyTestDay returns a day of hours at the given temperatures, with
+// no daily forecast.
+func agronomyTestDay(temperatures ...*float32) forecastDay {
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	day := forecastDay{Start: start, End: start.Add(time.Duration(len(temperatures)) * time.Hour)}
+	for _, t := range temperatures {
+		day.Hours = append(day.Hours, HourWeatherConditions{Temperature: t})
+	}
+	return day
+}
+
+func TestNewAgronomyDayMissingInputs(t *testing.T) {
+	// Without temperatures there are no degree days, frost risk or chill
+	// hours, and without cloud, wind and humidity there is no ET0.
+	row := newAgronomyDay(agronomyTestDay(nil, nil), 45, 10, 30, 0)
+	if row.TemperatureMin != nil || row.GrowingDegreeDays != nil || row.GrowingDegreeDaysHourly != nil || row.FrostRisk != nil || row.ChillHours != nil {
+		t.Errorf("day without temperatures = %+v, want no temperature metrics", row)
+	}
+	if row.SolarRadiation != nil || row.Et0 != nil || row.WaterBalance != nil {
+		t.Errorf("day without cloud cover = %+v, want no radiation or ET0", row)
+	}
+	if row.ExtraterrestrialRadiation <= 0 {
+		t.Errorf("extraterrestrial radiation = %g, want it computed from the date alone", row.ExtraterrestrialRadiation)
+	}
+
+	// An hour missing its temperature leaves the daily extremes to the
+	// other hours but rules out the hourly metrics.
+	row = newAgronomyDay(agronomyTestDay(float32Value(0), nil, float32Value(20)), 45, 10, 30, 0)
+	if row.GrowingDegreeDays == nil || *row.GrowingDegreeDays != 5 {
+		t.Errorf("degree days = %v, want 5 from the minimum raised to the base", row.GrowingDegreeDays)
+	}
+	if row.FrostRisk == nil || *row.FrostRisk != "high" {
+		t.Errorf("frost risk at exactly 0 °C = %v, want high", row.FrostRisk)
+	}
+	if row.GrowingDegreeDaysHourly != nil || row.ChillHours != nil {
+		t.Errorf("day with a missing hour has hourly degree days %v and chill hours %v, want neither", row.GrowingDegreeDaysHourly, row.ChillHours)
+	}
+
+	// With every hour, the hourly metrics are computed too. Hours exactly
+	// at the base count no degree days and hours at 0 °C are chill hours.
+	row = newAgronomyDay(agronomyTestDay(float32Value(0), float32Value(10), float32Value(22)), 45, 10, 30, 0)
+	if row.GrowingDegreeDaysHourly == nil || math.Abs(*row.GrowingDegreeDaysHourly-0.5) > 1e-9 {
+		t.Errorf("hourly degree days = %v, want 0.5", row.GrowingDegreeDaysHourly)
+	}
+	if row.ChillHours == nil || *row.ChillHours != 1 {
+		t.Errorf("chill hours = %v, want 1", row.ChillHours)
+	}
+}
+
+func TestAccumulateAgronomy(t *testing.T) {
+	gdd, hourly, chill := 4.0, 5.0, 3
+	days := []agronomyDay{
+		{GrowingDegreeDays: &gdd, GrowingDegreeDaysHourly: &hourly, ChillHours: &chill},
+		// A day missing its metrics carries the totals over.
+		{},
+		{GrowingDegreeDays: &gdd},
+	}
+	accumulateAgronomy(days)
+	// Hourly degree days are preferred to the daily estimate.
+	for i, want := range []float64{5, 5, 9} {
+		if days[i].GrowingDegreeDaysAccumulated != want {
+			t.Errorf("day %d accumulated degree days = %g, want %g", i, days[i].GrowingDegreeDaysAccumulated, want)
+		}
+		if days[i].ChillHoursAccumulated != 3 {
+			t.Errorf("day %d accumulated chill hours = %d, want 3", i, days[i].ChillHoursAccumulated)
+		}
+	}
+}
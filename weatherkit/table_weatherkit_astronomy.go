@@ -0,0 +1,332 @@
+package weatherkit
+
+import (
+	"context"
+	"time"
+
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/astronomy"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+)
+
+const (
+	// astronomyDefaultDays is the number of days returned when the query does not bound the date.
+	astronomyDefaultDays = 10
+	// astronomyMaxDays limits the number of days computed by a single query.
+	astronomyMaxDays = 3660
+)
+
+func weatherKitAstronomyColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "date",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The calendar date at midnight UTC. The events are those of the solar day whose solar noon is nearest to noon local mean solar time on this date at the location. Defaults to the next 10 days.",
+		},
+		{
+			Name:        "solar_noon",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is highest in the sky.",
+		},
+		{
+			Name:        "solar_midnight",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is lowest in the sky, following solar noon.",
+		},
+		{
+			Name:        "sunrise",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the top edge of the sun reaches the horizon in the morning.",
+		},
+		{
+			Name:        "sunset",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the top edge of the sun reaches the horizon in the evening.",
+		},
+		{
+			Name:        "sunrise_civil",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is 6 degrees below the horizon in the morning.",
+		},
+		{
+			Name:        "sunset_civil",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is 6 degrees below the horizon in the evening.",
+		},
+		{
+			Name:        "sunrise_nautical",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is 12 degrees below the horizon in the morning.",
+		},
+		{
+			Name:        "sunset_nautical",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is 12 degrees below the horizon in the evening.",
+		},
+		{
+			Name:        "sunrise_astronomical",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is 18 degrees below the horizon in the morning.",
+		},
+		{
+			Name:        "sunset_astronomical",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time when the sun is 18 degrees below the horizon in the evening.",
+		},
+		{
+			Name:        "day_length_seconds",
+			Type:        proto.ColumnType_INT,
+			Description: "The time between sunrise and sunset, in seconds.",
+		},
+		{
+			Name:        "golden_hour_morning_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the morning golden hour, when the sun is 4 degrees below the horizon.",
+		},
+		{
+			Name:        "golden_hour_morning_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the morning golden hour, when the sun is 6 degrees above the horizon.",
+		},
+		{
+			Name:        "golden_hour_evening_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the evening golden hour, when the sun is 6 degrees above the horizon.",
+		},
+		{
+			Name:        "golden_hour_evening_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the evening golden hour, when the sun is 4 degrees below the horizon.",
+		},
+		{
+			Name:        "blue_hour_morning_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the morning blue hour, when the sun is 6 degrees below the horizon.",
+		},
+		{
+			Name:        "blue_hour_morning_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the morning blue hour, when the sun is 4 degrees below the horizon.",
+		},
+		{
+			Name:        "blue_hour_evening_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the evening blue hour, when the sun is 4 degrees below the horizon.",
+		},
+		{
+			Name:        "blue_hour_evening_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the evening blue hour, when the sun is 6 degrees below the horizon.",
+		},
+		{
+			Name:        "moonrise",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time of moonrise on the day.",
+		},
+		{
+			Name:        "moonset",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time of moonset on the day.",
+		},
+		{
+			Name:        "moon_phase",
+			Type:        proto.ColumnType_STRING,
+			Description: "The phase of the moon at solar noon, using the same names as weatherkit_daily_forecast.",
+		},
+		{
+			Name:        "moon_phase_fraction",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The moon phase at solar noon, from 0 (new moon) through 0.5 (full moon) to 1.",
+		},
+		{
+			Name:        "moon_illumination",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The illuminated fraction of the moon at solar noon, from 0 to 1.",
+		},
+		{
+			Name:        "cross_check",
+			Type:        proto.ColumnType_BOOL,
+			Description: "If true, compare the computed events with the WeatherKit daily forecast for dates in the forecast range.",
+			Transform:   transform.FromQual("cross_check"),
+		},
+		{
+			Name:        "weatherkit_sunrise",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The sunrise reported by the WeatherKit daily forecast, when cross_check is true.",
+		},
+		{
+			Name:        "weatherkit_sunset",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The sunset reported by the WeatherKit daily forecast, when cross_check is true.",
+		},
+		{
+			Name:        "weatherkit_moon_phase",
+			Type:        proto.ColumnType_STRING,
+			Description: "The moon phase reported by the WeatherKit daily forecast, when cross_check is true.",
+		},
+		{
+			Name:        "sunrise_difference_seconds",
+			Type:        proto.ColumnType_INT,
+			Description: "The computed sunrise minus the WeatherKit sunrise, in seconds.",
+		},
+		{
+			Name:        "sunset_difference_seconds",
+			Type:        proto.ColumnType_INT,
+			Description: "The computed sunset minus the WeatherKit sunset, in seconds.",
+		},
+	}...)
+}
+
+func tableWeatherKitAstronomy() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_astronomy",
+		Description: "Sun and moon events computed locally for any date.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(locationKeyColumns(),
+				&plugin.KeyColumn{Name: "date", Require: plugin.Optional, Operators: []string{"=", ">", ">=", "<", "<="}},
+				&plugin.KeyColumn{Name: "cross_check", Require: plugin.Optional},
+			),
+			Hydrate: listAstronomy,
+		},
+		Columns: weatherKitAstronomyColumns(),
+	}
+}
+
+type astronomyRow struct {
+	queryLocation
+	Date                     time.Time
+	SolarNoon                time.Time
+	SolarMidnight            time.Time
+	Sunrise                  *time.Time
+	Sunset                   *time.Time
+	SunriseCivil             *time.Time
+	SunsetCivil              *time.Time
+	SunriseNautical          *time.Time
+	SunsetNautical           *time.Time
+	SunriseAstronomical      *time.Time
+	SunsetAstronomical       *time.Time
+	DayLengthSeconds         int64
+	GoldenHourMorningStart   *time.Time
+	GoldenHourMorningEnd     *time.Time
+	GoldenHourEveningStart   *time.Time
+	GoldenHourEveningEnd     *time.Time
+	BlueHourMorningStart     *time.Time
+	BlueHourMorningEnd       *time.Time
+	BlueHourEveningStart     *time.Time
+	BlueHourEveningEnd       *time.Time
+	Moonrise                 *time.Time
+	Moonset                  *time.Time
+	MoonPhase                string
+	MoonPhaseFraction        float64
+	MoonIllumination         float64
+	WeatherkitSunrise        *time.Time
+	WeatherkitSunset         *time.Time
+	WeatherkitMoonPhase      *string
+	SunriseDifferenceSeconds *int64
+	SunsetDifferenceSeconds  *int64
+}
+
+// newAstronomyRow computes the sun and moon events for a date at a location.
+func newAstronomyRow(date time.Time, location queryLocation) astronomyRow {
+	latitude, longitude := location.Latitude, location.Longitude
+	day := astronomy.NewSolarDay(date, latitude, longitude)
+	row := astronomyRow{
+		queryLocation: location,
+		Date:          date,
+		SolarNoon:     day.SolarNoon(),
+		SolarMidnight: day.SolarMidnight(),
+	}
+	crossings := func(alt float64) (*time.Time, *time.Time) {
+		rise, set, ok := day.Crossings(alt)
+		if !ok {
+			return nil, nil
+		}
+		return &rise, &set
+	}
+	row.Sunrise, row.Sunset = crossings(astronomy.SunriseAltitude)
+	row.SunriseCivil, row.SunsetCivil = crossings(astronomy.CivilAltitude)
+	row.SunriseNautical, row.SunsetNautical = crossings(astronomy.NauticalAltitude)
+	row.SunriseAstronomical, row.SunsetAstronomical = crossings(astronomy.AstronomicalAltitude)
+	row.BlueHourMorningStart, row.BlueHourEveningEnd = row.SunriseCivil, row.SunsetCivil
+	row.GoldenHourMorningStart, row.GoldenHourEveningEnd = crossings(astronomy.BlueHourAltitude)
+	row.BlueHourMorningEnd, row.BlueHourEveningStart = row.GoldenHourMorningStart, row.GoldenHourEveningEnd
+	row.GoldenHourMorningEnd, row.GoldenHourEveningStart = crossings(astronomy.GoldenHourAltitude)
+
+	if row.Sunrise != nil {
+		row.DayLengthSeconds = int64(row.Sunset.Sub(*row.Sunrise).Seconds())
+	} else if alt, _ := astronomy.SunPosition(row.SolarNoon, latitude, longitude); alt > astronomy.SunriseAltitude {
+		// Polar day
+		row.DayLengthSeconds = int64((24 * time.Hour).Seconds())
+	}
+
+	// The day starts at midnight local mean time.
+	start := date.Add(-time.Duration(longitude / 15 * float64(time.Hour)))
+	row.Moonrise, row.Moonset = astronomy.MoonTimes(start, latitude, longitude)
+	row.MoonIllumination, row.MoonPhaseFraction = astronomy.MoonIllumination(row.SolarNoon)
+	row.MoonPhase = astronomy.MoonPhaseName(row.MoonPhaseFraction)
+	return row
+}
+
+// crossCheck copies the WeatherKit values for the forecast day whose solar
+// noon is within 12 hours of the computed solar noon.
+func (row *astronomyRow) crossCheck(days []DayWeatherConditions) {
+	for _, day := range days {
+		sunrise := parseTime(day.Sunrise)
+		sunset := parseTime(day.Sunset)
+		solarNoon := parseTime(day.SolarNoon)
+		if solarNoon == nil || solarNoon.Sub(row.SolarNoon).Abs() > 12*time.Hour {
+			continue
+		}
+		row.WeatherkitSunrise = sunrise
+		row.WeatherkitSunset = sunset
+		row.WeatherkitMoonPhase = day.MoonPhase
+		if sunrise != nil && row.Sunrise != nil {
+			diff := int64(row.Sunrise.Sub(*sunrise).Seconds())
+			row.SunriseDifferenceSeconds = &diff
+		}
+		if sunset != nil && row.Sunset != nil {
+			diff := int64(row.Sunset.Sub(*sunset).Seconds())
+			row.SunsetDifferenceSeconds = &diff
+		}
+		return
+	}
+}
+
+func listAstronomy(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start, end, err := dateRangeQuals(d, "date", today, astronomyDefaultDays, astronomyMaxDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var service *Client
+	if d.KeyColumnQuals["cross_check"].GetBoolValue() {
+		service, err = connect(ctx, d)
+		if err != nil {
+			logger.Error("Invalid credentials.")
+			return nil, err
+		}
+	}
+
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		var days []DayWeatherConditions
+		if service != nil {
+			weather, err := service.DailyForecast(ctx, location.Latitude, location.Longitude)
+			if err != nil {
+				return nil, err
+			}
+			days = weather.DailyForecast.Days
+		}
+		var rows []interface{}
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			row := newAstronomyRow(date, location)
+			row.crossCheck(days)
+			rows = append(rows, row)
+		}
+		return rows, nil
+	})
+	return nil, err
+}
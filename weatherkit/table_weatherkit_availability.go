@@ -4,29 +4,17 @@ import (
 	"context"
 	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
-	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
 )
 
 func weatherKitAvailabilityColumns() []*plugin.Column {
-	return []*plugin.Column{
-		{
-			Name:        "latitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the latitude of the coordinate between -90 and 90.",
-			Transform:   transform.FromQual("latitude"),
-		},
-		{
-			Name:        "longitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the longitude of the coordinate between -180 and 180.",
-			Transform:   transform.FromQual("longitude"),
-		},
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
 		{
 			Name:        "data_set",
 			Type:        proto.ColumnType_STRING,
 			Description: "The collection of weather information for a location.",
 		},
-	}
+	}...)
 }
 
 func tableWeatherKitAvailability() *plugin.Table {
@@ -34,7 +22,7 @@ func tableWeatherKitAvailability() *plugin.Table {
 		Name:        "weatherkit_availability",
 		Description: "WeatherKit Availability.",
 		List: &plugin.ListConfig{
-			KeyColumns: plugin.AllColumns([]string{"latitude", "longitude"}),
+			KeyColumns: locationKeyColumns(),
 			Hydrate:    listAvailability,
 		},
 		Columns: weatherKitAvailabilityColumns(),
@@ -44,20 +32,23 @@ func tableWeatherKitAvailability() *plugin.Table {
 func listAvailability(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
 	logger := plugin.Logger(ctx)
 	service, _ := connect(ctx, d)
-	latitude := d.KeyColumnQuals["latitude"].GetDoubleValue()
-	longitude := d.KeyColumnQuals["longitude"].GetDoubleValue()
-	dataSet, err := service.Availability(ctx, latitude, longitude)
-	if err != nil {
-		logger.Error("listAvailability", "got error", err)
-		return nil, err
-	}
 
 	type Row struct {
+		queryLocation
 		DataSet string `json:"dataSet,omitempty"`
 	}
 
-	for _, data := range dataSet {
-		d.StreamListItem(ctx, Row{DataSet: data})
-	}
-	return nil, nil
+	err := streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		dataSet, err := service.Availability(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			logger.Error("listAvailability", "got error", err)
+			return nil, err
+		}
+		var rows []interface{}
+		for _, data := range dataSet {
+			rows = append(rows, Row{queryLocation: location, DataSet: data})
+		}
+		return rows, nil
+	})
+	return nil, err
 }
@@ -0,0 +1,68 @@
+package weatherkit
+
+import (
+	"context"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+)
+
+func weatherKitConditionCodeColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "code",
+			Type:        proto.ColumnType_STRING,
+			Description: "The condition code as returned in the condition_code column.",
+		},
+		{
+			Name:        "description",
+			Type:        proto.ColumnType_STRING,
+			Description: "A human-readable description of the condition.",
+		},
+		{
+			Name:        "category",
+			Type:        proto.ColumnType_STRING,
+			Description: "The category of the condition: clear, cloud, visibility, wind, rain, snow, storm, or hazard.",
+		},
+		{
+			Name:        "severity",
+			Type:        proto.ColumnType_INT,
+			Description: "A rank of the severity of the condition, from 0 (benign) to 5 (life threatening).",
+		},
+		{
+			Name:        "day_icon",
+			Type:        proto.ColumnType_STRING,
+			Description: "The SF Symbols icon name for the condition during the day.",
+		},
+		{
+			Name:        "night_icon",
+			Type:        proto.ColumnType_STRING,
+			Description: "The SF Symbols icon name for the condition during the night.",
+		},
+		{
+			Name:        "emoji",
+			Type:        proto.ColumnType_STRING,
+			Description: "An emoji representing the condition.",
+		},
+	}
+}
+
+func tableWeatherKitConditionCode() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_condition_code",
+		Description: "WeatherKit Condition Codes.",
+		List: &plugin.ListConfig{
+			Hydrate: listConditionCode,
+		},
+		Columns: weatherKitConditionCodeColumns(),
+	}
+}
+
+func listConditionCode(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	for _, code := range conditionCodes {
+		d.StreamListItem(ctx, code)
+		if plugin.IsCancelled(ctx) {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
@@ -8,23 +8,19 @@ import (
 )
 
 func weatherKitCurrentWeatherColumns() []*plugin.Column {
-	return []*plugin.Column{
-		{
-			Name:        "latitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the latitude of the coordinate between -90 and 90.",
-			Transform:   transform.FromQual("latitude"),
-		},
-		{
-			Name:        "longitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the longitude of the coordinate between -180 and 180.",
-			Transform:   transform.FromQual("longitude"),
-		},
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, currentWeatherColumns()...)
+}
+
+// currentWeatherColumns returns the current weather columns that do not describe
+// the location, so that the grid table can share them.
+func currentWeatherColumns() []*plugin.Column {
+	columns := []*plugin.Column{
 		{
 			Name:        "as_of",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The date and time.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "cloud_cover",
@@ -36,6 +32,7 @@ func weatherKitCurrentWeatherColumns() []*plugin.Column {
 			Type:        proto.ColumnType_STRING,
 			Description: "An enumeration value indicating the condition at the time.",
 		},
+		conditionDescriptionColumn(),
 		{
 			Name:        "daylight",
 			Type:        proto.ColumnType_BOOL,
@@ -49,12 +46,12 @@ func weatherKitCurrentWeatherColumns() []*plugin.Column {
 		{
 			Name:        "precipitation_intensity",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The precipitation intensity, in millimeters per hour.",
+			Description: "The precipitation intensity, in millimeters per hour, or inches per hour (imperial).",
 		},
 		{
 			Name:        "pressure",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The sea level air pressure, in millibars.",
+			Description: "The sea level air pressure, in millibars, inches of mercury (imperial), or pascals (si).",
 		},
 		{
 			Name:        "pressure_trend",
@@ -64,17 +61,17 @@ func weatherKitCurrentWeatherColumns() []*plugin.Column {
 		{
 			Name:        "temperature",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The current temperature, in degrees Celsius.",
+			Description: "The current temperature, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
 		{
 			Name:        "temperature_apparent",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The feels-like temperature when factoring wind and humidity, in degrees Celsius.",
+			Description: "The feels-like temperature when factoring wind and humidity, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
 		{
 			Name:        "temperature_dew_point",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The temperature at which relative humidity is 100%, in Celsius.",
+			Description: "The temperature at which relative humidity is 100%, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
 		{
 			Name:        "uv_index",
@@ -84,7 +81,7 @@ func weatherKitCurrentWeatherColumns() []*plugin.Column {
 		{
 			Name:        "visibility",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The distance at which terrain is visible, in meters.",
+			Description: "The distance at which terrain is visible, in meters, or miles (imperial).",
 		},
 		{
 			Name:        "wind_direction",
@@ -94,55 +91,74 @@ func weatherKitCurrentWeatherColumns() []*plugin.Column {
 		{
 			Name:        "wind_gust",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The maximum wind gust speed, in kilometers per hour.",
+			Description: "The maximum wind gust speed, in kilometers per hour, miles per hour (imperial), or meters per second (si).",
 		},
 		{
 			Name:        "wind_speed",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The wind speed, in kilometers per hour.",
+			Description: "The wind speed, in kilometers per hour, miles per hour (imperial), or meters per second (si).",
 		},
-		{
+	}
+	columns = append(columns, comfortIndexColumns()...)
+	return append(columns,
+		unitsColumn(),
+		&plugin.Column{
 			Name:        "metadata",
 			Type:        proto.ColumnType_JSON,
 			Description: "Descriptive information about the weather data.",
 		},
-	}
+	)
 }
 
 func tableWeatherKitCurrentWeather() *plugin.Table {
 	return &plugin.Table{
 		Name:        "weatherkit_current_weather",
 		Description: "WeatherKit Current Weather.",
-		Get: &plugin.GetConfig{
-			KeyColumns: plugin.AllColumns([]string{"latitude", "longitude"}),
-			Hydrate:    getCurrentWeather,
+		List: &plugin.ListConfig{
+			KeyColumns: weatherKeyColumns(),
+			Hydrate:    listCurrentWeather,
 		},
 		Columns: weatherKitCurrentWeatherColumns(),
 	}
 }
 
-func getCurrentWeather(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+func listCurrentWeather(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
 	logger := plugin.Logger(ctx)
 	service, err := connect(ctx, d)
 	if err != nil {
 		logger.Error("Invalid credentials.")
 		return nil, err
 	}
-	latitude := d.KeyColumnQuals["latitude"].GetDoubleValue()
-	longitude := d.KeyColumnQuals["longitude"].GetDoubleValue()
-	weather, _ := service.CurrentWeather(ctx, latitude, longitude)
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
 	type Row struct {
 		CurrentWeatherData
-		Latitude  float32         `json:"latitude"`
-		Longitude float32         `json:"longitude"`
-		Metadata  WeatherMetadata `json:"metadata,omitempty"`
+		ComfortIndices
+		queryLocation
+		Units    unitSystem      `json:"units"`
+		Metadata WeatherMetadata `json:"metadata,omitempty"`
 	}
-	row := Row{
-		CurrentWeatherData: weather.CurrentWeather,
-		Latitude:           *weather.CurrentWeather.Metadata.Latitude,
-		Longitude:          *weather.CurrentWeather.Metadata.Longitude,
-		Metadata:           weather.CurrentWeather.Metadata,
-	}
-
-	return row, nil
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.CurrentWeather(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		converter, err := newUnitConverter(weather.CurrentWeather.Metadata, units)
+		if err != nil {
+			return nil, err
+		}
+		metric := converter.toMetric().currentWeather(weather.CurrentWeather)
+		comfort := newComfortIndices(metric.Temperature, metric.Humidity, metric.TemperatureDewPoint, metric.WindSpeed)
+		row := Row{
+			CurrentWeatherData: converter.currentWeather(weather.CurrentWeather),
+			ComfortIndices:     converter.fromMetric().comfortIndices(comfort),
+			queryLocation:      location,
+			Units:              units,
+			Metadata:           weather.CurrentWeather.Metadata,
+		}
+		return []interface{}{row}, nil
+	})
+	return nil, err
 }
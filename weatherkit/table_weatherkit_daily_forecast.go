@@ -5,27 +5,18 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+	"time"
 )
 
 func weatherKitDailyForecastColumns() []*plugin.Column {
-	return []*plugin.Column{
-		{
-			Name:        "latitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the latitude of the coordinate between -90 and 90.",
-			Transform:   transform.FromQual("latitude"),
-		},
-		{
-			Name:        "longitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the longitude of the coordinate between -180 and 180.",
-			Transform:   transform.FromQual("longitude"),
-		},
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
 		{
 			Name:        "condition_code",
 			Type:        proto.ColumnType_STRING,
 			Description: "An enumeration value indicating the condition at the time.",
 		},
+		conditionDescriptionColumn(),
 		{
 			Name:        "daytime_forecast",
 			Type:        proto.ColumnType_JSON,
@@ -35,11 +26,13 @@ func weatherKitDailyForecastColumns() []*plugin.Column {
 			Name:        "forecast_end",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The ending date and time of the day.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "forecast_start",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The starting date and time of the day.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "max_uv_index",
@@ -55,11 +48,13 @@ func weatherKitDailyForecastColumns() []*plugin.Column {
 			Name:        "moonrise",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time of moonrise on the specified day.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "moonset",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time of moonset on the specified day.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "overnight_forecast",
@@ -69,7 +64,7 @@ func weatherKitDailyForecastColumns() []*plugin.Column {
 		{
 			Name:        "precipitation_amount",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The amount of precipitation forecasted to occur during the day, in millimeters.",
+			Description: "The amount of precipitation forecasted to occur during the day, in millimeters, or inches (imperial).",
 		},
 		{
 			Name:        "precipitation_chance",
@@ -84,74 +79,135 @@ func weatherKitDailyForecastColumns() []*plugin.Column {
 		{
 			Name:        "snowfall_amount",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The depth of snow as ice crystals forecasted to occur during the day, in millimeters.",
+			Description: "The depth of snow as ice crystals forecasted to occur during the day, in millimeters, or inches (imperial).",
 		},
 		{
 			Name:        "solar_midnight",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is lowest in the sky.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "solar_noon",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is highest in the sky.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunrise",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the top edge of the sun reaches the horizon in the morning.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunrise_astronomical",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is 18 degrees below the horizon in the morning.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunrise_civil",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is 6 degrees below the horizon in the morning.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunrise_nautical",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is 12 degrees below the horizon in the morning.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunset",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the top edge of the sun reaches the horizon in the evening.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunset_astronomical",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is 18 degrees below the horizon in the evening.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunset_civil",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is 6 degrees below the horizon in the evening.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "sunset_nautical",
-			Type:        proto.ColumnType_STRING,
+			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the sun is 12 degrees below the horizon in the evening.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
+		},
+		{
+			Name:        "day_length_seconds",
+			Type:        proto.ColumnType_INT,
+			Description: "The time between sunrise and sunset, in seconds.",
+		},
+		{
+			Name:        "daylight_remaining",
+			Type:        proto.ColumnType_INT,
+			Description: "The daylight remaining in the day as of the query, from 0 to day_length_seconds, in seconds.",
+		},
+		{
+			Name:        "civil_twilight_duration",
+			Type:        proto.ColumnType_INT,
+			Description: "The total time of morning and evening civil twilight, in seconds.",
 		},
 		{
 			Name:        "temperature_max",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The maximum temperature forecasted to occur during the day, in degrees Celsius.",
+			Description: "The maximum temperature forecasted to occur during the day, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
 		{
 			Name:        "temperature_min",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The minimum temperature forecasted to occur during the day, in degrees Celsius.",
+			Description: "The minimum temperature forecasted to occur during the day, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
+		unitsColumn(),
 		{
 			Name:        "metadata",
 			Type:        proto.ColumnType_JSON,
 			Description: "Descriptive information about the weather data.",
 		},
+	}...)
+}
+
+// Daylight is the length of daylight and twilight derived from the solar events of a day.
+type Daylight struct {
+	DayLengthSeconds      *int64 `json:"dayLengthSeconds,omitempty"`
+	DaylightRemaining     *int64 `json:"daylightRemaining,omitempty"`
+	CivilTwilightDuration *int64 `json:"civilTwilightDuration,omitempty"`
+}
+
+func newDaylight(day DayWeatherConditions, now time.Time) Daylight {
+	var daylight Daylight
+	seconds := func(d time.Duration) *int64 {
+		s := int64(d.Seconds())
+		return &s
+	}
+	sunrise := parseTime(day.Sunrise)
+	sunset := parseTime(day.Sunset)
+	if sunrise == nil || sunset == nil {
+		return daylight
+	}
+	daylight.DayLengthSeconds = seconds(sunset.Sub(*sunrise))
+	switch {
+	case now.Before(*sunrise):
+		daylight.DaylightRemaining = daylight.DayLengthSeconds
+	case now.After(*sunset):
+		daylight.DaylightRemaining = seconds(0)
+	default:
+		daylight.DaylightRemaining = seconds(sunset.Sub(now))
+	}
+	sunriseCivil := parseTime(day.SunriseCivil)
+	sunsetCivil := parseTime(day.SunsetCivil)
+	if sunriseCivil != nil && sunsetCivil != nil {
+		daylight.CivilTwilightDuration = seconds(sunrise.Sub(*sunriseCivil) + sunsetCivil.Sub(*sunset))
 	}
+	return daylight
 }
 
 func tableWeatherKitDailyForecast() *plugin.Table {
@@ -159,7 +215,7 @@ func tableWeatherKitDailyForecast() *plugin.Table {
 		Name:        "weatherkit_daily_forecast",
 		Description: "WeatherKit Daily Forecast.",
 		List: &plugin.ListConfig{
-			KeyColumns: plugin.AllColumns([]string{"latitude", "longitude"}),
+			KeyColumns: weatherKeyColumns(),
 			Hydrate:    listDailyForecast,
 		},
 		Columns: weatherKitDailyForecastColumns(),
@@ -173,23 +229,38 @@ func listDailyForecast(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydra
 		logger.Error("Invalid credentials.")
 		return nil, err
 	}
-	latitude := d.KeyColumnQuals["latitude"].GetDoubleValue()
-	longitude := d.KeyColumnQuals["longitude"].GetDoubleValue()
-	weather, _ := service.DailyForecast(ctx, latitude, longitude)
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
 	type Row struct {
 		DayWeatherConditions
-		WeatherMetadata
+		Daylight
+		queryLocation
+		Units    unitSystem      `json:"units"`
+		Metadata WeatherMetadata `json:"metadata,omitempty"`
 	}
-	for _, day := range weather.DailyForecast.Days {
-		row := Row{
-			DayWeatherConditions: day,
-			WeatherMetadata:      weather.DailyForecast.Metadata,
+	now := time.Now()
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.DailyForecast(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			return nil, err
 		}
-		d.StreamListItem(ctx, row)
-		if plugin.IsCancelled(ctx) {
-			logger.Trace("CANCELLED!")
-			return nil, nil
+		converter, err := newUnitConverter(weather.DailyForecast.Metadata, units)
+		if err != nil {
+			return nil, err
 		}
-	}
-	return nil, nil
+		var rows []interface{}
+		for _, day := range weather.DailyForecast.Days {
+			rows = append(rows, Row{
+				DayWeatherConditions: converter.dayWeatherConditions(day),
+				Daylight:             newDaylight(day, now),
+				queryLocation:        location,
+				Units:                units,
+				Metadata:             weather.DailyForecast.Metadata,
+			})
+		}
+		return rows, nil
+	})
+	return nil, err
 }
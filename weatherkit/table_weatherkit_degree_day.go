@@ -0,0 +1,256 @@
+package weatherkit
+
+import (
+	"context"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"math"
+	"time"
+)
+
+// Degree day methods.
+const (
+	degreeDayMethodHourly = "hourly"
+	degreeDayMethodMinMax = "min_max"
+)
+
+// defaultDegreeDayBase is the default base temperature of each unit system:
+// 18°C, and 65°F as used in the United States.
+var defaultDegreeDayBase = map[unitSystem]float64{
+	unitsMetric:   18,
+	unitsImperial: 65,
+	unitsSI:       291.15,
+}
+
+func weatherKitDegreeDayColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the forecast day, at local midnight.",
+		},
+		{
+			Name:        "forecast_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the forecast day.",
+		},
+		{
+			Name:        "base_temperature",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The base temperature of the degree days, in the units of the units column. Defaults to 18 degrees Celsius, 65 degrees Fahrenheit (imperial), or 291.15 kelvin (si).",
+		},
+		{
+			Name:        "temperature_min",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The minimum temperature of the day, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "temperature_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The maximum temperature of the day, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "heating_degree_days_min_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The heating degree days from the mean of the minimum and maximum temperatures.",
+		},
+		{
+			Name:        "cooling_degree_days_min_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The cooling degree days from the mean of the minimum and maximum temperatures.",
+		},
+		{
+			Name:        "heating_degree_days_hourly",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The heating degree days integrated over the hourly temperatures, or null if the hourly forecast does not cover the whole day.",
+		},
+		{
+			Name:        "cooling_degree_days_hourly",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The cooling degree days integrated over the hourly temperatures, or null if the hourly forecast does not cover the whole day.",
+		},
+		{
+			Name:        "method",
+			Type:        proto.ColumnType_STRING,
+			Description: "The method of the heating_degree_days and cooling_degree_days columns: hourly where the hourly forecast covers the whole day, and min_max otherwise.",
+		},
+		{
+			Name:        "heating_degree_days",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The heating degree days of the day, by the most accurate method available.",
+		},
+		{
+			Name:        "cooling_degree_days",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The cooling degree days of the day, by the most accurate method available.",
+		},
+		{
+			Name:        "week_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The local date of the Monday starting the week of the day.",
+		},
+		{
+			Name:        "heating_degree_days_week",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The total heating degree days of the forecast days in the week.",
+		},
+		{
+			Name:        "cooling_degree_days_week",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The total cooling degree days of the forecast days in the week.",
+		},
+		{
+			Name:        "heating_degree_days_accumulated",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The heating degree days accumulated from the first forecast day.",
+		},
+		{
+			Name:        "cooling_degree_days_accumulated",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The cooling degree days accumulated from the first forecast day.",
+		},
+		unitsColumn(),
+	}...)
+}
+
+func tableWeatherKitDegreeDay() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_degree_day",
+		Description: "Heating and cooling degree days from the WeatherKit daily and hourly forecasts.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(weatherKeyColumns(),
+				&plugin.KeyColumn{Name: "base_temperature", Require: plugin.Optional},
+			),
+			Hydrate: listDegreeDay,
+		},
+		Columns: weatherKitDegreeDayColumns(),
+	}
+}
+
+// degreeDay holds the heating and cooling degree days of a forecast day.
+type degreeDay struct {
+	ForecastStart                time.Time
+	ForecastEnd                  time.Time
+	BaseTemperature              float64
+	TemperatureMin               *float64
+	TemperatureMax               *float64
+	HeatingDegreeDaysMinMax      *float64
+	CoolingDegreeDaysMinMax      *float64
+	HeatingDegreeDaysHourly      *float64
+	CoolingDegreeDaysHourly      *float64
+	Method                       *string
+	HeatingDegreeDays            *float64
+	CoolingDegreeDays            *float64
+	WeekStart                    time.Time
+	HeatingDegreeDaysWeek        float64
+	CoolingDegreeDaysWeek        float64
+	HeatingDegreeDaysAccumulated float64
+	CoolingDegreeDaysAccumulated float64
+}
+
+// newDegreeDay computes the degree days of a day. tz gives the local date of
+// the day for its week.
+func newDegreeDay(day forecastDay, base float64, tz *time.Location) degreeDay {
+	local := day.Start.In(tz).Add(day.End.Sub(day.Start) / 2)
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	row := degreeDay{
+		ForecastStart:   day.Start,
+		ForecastEnd:     day.End,
+		BaseTemperature: base,
+		TemperatureMin:  float64Ptr(day.Day.TemperatureMin),
+		TemperatureMax:  float64Ptr(day.Day.TemperatureMax),
+		// Weeks start on Monday.
+		WeekStart: date.AddDate(0, 0, -(int(date.Weekday())+6)%7),
+	}
+	if row.TemperatureMin != nil && row.TemperatureMax != nil {
+		mean := (*row.TemperatureMin + *row.TemperatureMax) / 2
+		hdd, cdd := math.Max(0, base-mean), math.Max(0, mean-base)
+		row.HeatingDegreeDaysMinMax, row.CoolingDegreeDaysMinMax = &hdd, &cdd
+		method := degreeDayMethodMinMax
+		row.Method, row.HeatingDegreeDays, row.CoolingDegreeDays = &method, &hdd, &cdd
+	}
+	if day.complete() {
+		var hdd, cdd float64
+		for _, hour := range day.Hours {
+			if hour.Temperature == nil {
+				return row
+			}
+			t := float64(*hour.Temperature)
+			hdd += math.Max(0, base-t) / float64(len(day.Hours))
+			cdd += math.Max(0, t-base) / float64(len(day.Hours))
+		}
+		row.HeatingDegreeDaysHourly, row.CoolingDegreeDaysHourly = &hdd, &cdd
+		method := degreeDayMethodHourly
+		row.Method, row.HeatingDegreeDays, row.CoolingDegreeDays = &method, &hdd, &cdd
+	}
+	return row
+}
+
+// totalDegreeDays sets the weekly and accumulated totals of the days.
+func totalDegreeDays(days []degreeDay) {
+	type total struct{ heating, cooling float64 }
+	weeks := map[time.Time]total{}
+	var accumulated total
+	for i := range days {
+		week := weeks[days[i].WeekStart]
+		if days[i].HeatingDegreeDays != nil {
+			week.heating += *days[i].HeatingDegreeDays
+			accumulated.heating += *days[i].HeatingDegreeDays
+		}
+		if days[i].CoolingDegreeDays != nil {
+			week.cooling += *days[i].CoolingDegreeDays
+			accumulated.cooling += *days[i].CoolingDegreeDays
+		}
+		weeks[days[i].WeekStart] = week
+		days[i].HeatingDegreeDaysAccumulated = accumulated.heating
+		days[i].CoolingDegreeDaysAccumulated = accumulated.cooling
+	}
+	for i := range days {
+		days[i].HeatingDegreeDaysWeek = weeks[days[i].WeekStart].heating
+		days[i].CoolingDegreeDaysWeek = weeks[days[i].WeekStart].cooling
+	}
+}
+
+func listDegreeDay(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	base := defaultDegreeDayBase[units]
+	if q, ok := d.KeyColumnQuals["base_temperature"]; ok {
+		base = q.GetDoubleValue()
+	}
+	type Row struct {
+		degreeDay
+		queryLocation
+		Units unitSystem `json:"units"`
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		tz, err := locationTimezone(location, "")
+		if err != nil {
+			return nil, err
+		}
+		days, _, err := fetchForecastDays(ctx, service, location, units)
+		if err != nil {
+			return nil, err
+		}
+		degreeDays := make([]degreeDay, len(days))
+		for i, day := range days {
+			degreeDays[i] = newDegreeDay(day, base, tz)
+		}
+		totalDegreeDays(degreeDays)
+		rows := make([]interface{}, len(degreeDays))
+		for i, dd := range degreeDays {
+			rows[i] = Row{degreeDay: dd, queryLocation: location, Units: units}
+		}
+		return rows, nil
+	})
+	return nil, err
+}
@@ -0,0 +1,137 @@
+package weatherkit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// degreeDayTestDay returns the day from start with the given daily extremes
+// and hourly temperatures.
+func degreeDayTestDay(start time.Time, min, max *float32, hours ...*float32) forecastDay {
+	day := forecastDay{
+		Start: start,
+		End:   start.Add(24 * time.Hour),
+		Day:   DayWeatherConditions{TemperatureMin: min, TemperatureMax: max},
+	}
+	for _, t := range hours {
+		day.Hours = append(day.Hours, HourWeatherConditions{Temperature: t})
+	}
+	return day
+}
+
+// uniformHours returns n hours at the temperature.
+func uniformHours(n int, t float32) []*float32 {
+	hours := make([]*float32, n)
+	for i := range hours {
+		hours[i] = float32Value(t)
+	}
+	return hours
+}
+
+func TestNewDegreeDay(t *testing.T) {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	// Half the hours 8 degrees below 18°C and half 8 above.
+	mixed := append(uniformHours(12, 10), uniformHours(12, 26)...)
+	tests := []struct {
+		name             string
+		day              forecastDay
+		base             float64
+		method           string
+		heating, cooling float64
+	}{
+		// The examples of the NOAA Climate Prediction Center: a high of
+		// 60°F and a low of 40°F make 15 heating degree days, and a high of
+		// 90°F and a low of 70°F make 15 cooling degree days.
+		{"heating", degreeDayTestDay(start, float32Value(40), float32Value(60)), defaultDegreeDayBase[unitsImperial], degreeDayMethodMinMax, 15, 0},
+		{"cooling", degreeDayTestDay(start, float32Value(70), float32Value(90)), defaultDegreeDayBase[unitsImperial], degreeDayMethodMinMax, 0, 15},
+		// The same day in Celsius against the metric default base.
+		{"metric heating", degreeDayTestDay(start, float32Value(4.4), float32Value(15.6)), defaultDegreeDayBase[unitsMetric], degreeDayMethodMinMax, 8, 0},
+		{"mean at the base", degreeDayTestDay(start, float32Value(10), float32Value(26)), 18, degreeDayMethodMinMax, 0, 0},
+		// The hourly method counts the degrees of each hour either side of
+		// the base, where the daily mean is at it.
+		{"hourly", degreeDayTestDay(start, float32Value(10), float32Value(26), mixed...), 18, degreeDayMethodHourly, 4, 4},
+		{"hourly at the base", degreeDayTestDay(start, nil, nil, uniformHours(24, 18)...), 18, degreeDayMethodHourly, 0, 0},
+		// A day with an hour missing its temperature, or missing hours,
+		// falls back to the daily extremes.
+		{"missing hour temperature", degreeDayTestDay(start, float32Value(10), float32Value(20), append(uniformHours(23, 10), nil)...), 18, degreeDayMethodMinMax, 3, 0},
+		{"incomplete day", degreeDayTestDay(start, float32Value(10), float32Value(20), uniformHours(12, 10)...), 18, degreeDayMethodMinMax, 3, 0},
+	}
+	for _, tt := range tests {
+		row := newDegreeDay(tt.day, tt.base, time.UTC)
+		if row.Method == nil || *row.Method != tt.method {
+			t.Errorf("%s: method = %v, want %s", tt.name, row.Method, tt.method)
+			continue
+		}
+		if math.Abs(*row.HeatingDegreeDays-tt.heating) > 0.05 || math.Abs(*row.CoolingDegreeDays-tt.cooling) > 0.05 {
+			t.Errorf("%s: degree days = %g heating, %g cooling, want %g and %g", tt.name, *row.HeatingDegreeDays, *row.CoolingDegreeDays, tt.heating, tt.cooling)
+		}
+	}
+
+	// Without temperatures there are no degree days.
+	row := newDegreeDay(degreeDayTestDay(start, nil, nil, uniformHours(12, 10)...), 18, time.UTC)
+	if row.Method != nil || row.HeatingDegreeDays != nil || row.CoolingDegreeDays != nil {
+		t.Errorf("day without temperatures = %+v, want no degree days", row)
+	}
+}
+
+func TestDefaultDegreeDayBase(t *testing.T) {
+	// The United States uses 65°F, and most other countries 18°C.
+	imperial, metric := defaultDegreeDayBase[unitsImperial], defaultDegreeDayBase[unitsMetric]
+	if imperial != 65 || metric != 18 {
+		t.Errorf("default bases = %g°F and %g°C, want 65°F and 18°C", imperial, metric)
+	}
+	if si := defaultDegreeDayBase[unitsSI]; math.Abs(si-(metric+273.15)) > 1e-9 {
+		t.Errorf("SI default base = %g K, want the metric base", si)
+	}
+}
+
+func TestTotalDegreeDays(t *testing.T) {
+	// 1 November 2024 is a Friday.
+	start := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	var days []degreeDay
+	for i, max := range []float32{16, 14, 12, 0, 10, 8} {
+		day := degreeDayTestDay(start.AddDate(0, 0, i), float32Value(max), float32Value(max))
+		if max == 0 {
+			// A day without a forecast adds nothing.
+			day.Day = DayWeatherConditions{}
+		}
+		days = append(days, newDegreeDay(day, 18, time.UTC))
+	}
+	totalDegreeDays(days)
+
+	monday := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	want := []struct {
+		weekStart         time.Time
+		week, accumulated float64
+	}{
+		{monday.AddDate(0, 0, -7), 12, 2},
+		{monday.AddDate(0, 0, -7), 12, 6},
+		{monday.AddDate(0, 0, -7), 12, 12},
+		{monday, 18, 12},
+		{monday, 18, 20},
+		{monday, 18, 30},
+	}
+	for i, w := range want {
+		d := days[i]
+		if !d.WeekStart.Equal(w.weekStart) || d.HeatingDegreeDaysWeek != w.week || d.HeatingDegreeDaysAccumulated != w.accumulated {
+			t.Errorf("day %d: week of %s with %g heating degree days, %g accumulated, want week of %s with %g, %g accumulated", i, d.WeekStart.Format("2006-01-02"), d.HeatingDegreeDaysWeek, d.HeatingDegreeDaysAccumulated, w.weekStart.Format("2006-01-02"), w.week, w.accumulated)
+		}
+		if d.CoolingDegreeDaysWeek != 0 || d.CoolingDegreeDaysAccumulated != 0 {
+			t.Errorf("day %d: cooling degree days %g week, %g accumulated, want none", i, d.CoolingDegreeDaysWeek, d.CoolingDegreeDaysAccumulated)
+		}
+	}
+}
+
+func TestDegreeDayWeekTimeZone(t *testing.T) {
+	// Local midnight on Monday 4 November in Tokyo is still Sunday in UTC.
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	start := time.Date(2024, 11, 4, 0, 0, 0, 0, tokyo)
+	row := newDegreeDay(degreeDayTestDay(start, nil, nil), 18, tokyo)
+	if want := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC); !row.WeekStart.Equal(want) {
+		t.Errorf("week start = %s, want %s", row.WeekStart, want)
+	}
+}
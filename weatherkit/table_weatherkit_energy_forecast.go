@@ -0,0 +1,364 @@
+package weatherkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/astronomy"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/energy"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+	"time"
+)
+
+const (
+	// energyForecastDefaultHours is the number of hours returned when the
+	// query does not bound forecast_start.
+	energyForecastDefaultHours = 48
+
+	defaultPVCapacityKw      = 1
+	defaultPVTilt            = 30
+	defaultTurbineCapacityKw = 1
+	defaultHubHeight         = 20
+	defaultWindShear         = 1.0 / 7
+
+	// windReferenceHeight is the height of forecast wind speeds, in meters.
+	windReferenceHeight = 10
+	// irradianceSamples is the number of sun positions averaged over each
+	// hour, so that hours around sunrise and sunset are not over or under
+	// counted.
+	irradianceSamples = 4
+)
+
+func weatherKitEnergyForecastColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the forecast hour.",
+		},
+		{
+			Name:        "pv_capacity_kw",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The peak capacity of the solar panels, in kilowatts. Defaults to 1, giving the output per kilowatt peak.",
+		},
+		{
+			Name:        "pv_tilt",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The tilt of the panels from horizontal, in degrees. Defaults to 30.",
+		},
+		{
+			Name:        "pv_azimuth",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The compass bearing the panels face, in degrees. Defaults to 180 (south) in the northern hemisphere and 0 (north) in the southern hemisphere.",
+		},
+		{
+			Name:        "turbine_capacity_kw",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The rated capacity of the wind turbine, in kilowatts, for a generic power curve reaching it at 12 m/s. Defaults to 1, or to the highest output of power_curve.",
+		},
+		{
+			Name:        "hub_height",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The hub height of the wind turbine, in meters. Defaults to 20.",
+		},
+		{
+			Name:        "wind_shear",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The wind shear exponent used to extrapolate the 10 meter wind speed to the hub height. Defaults to 1/7, typical of open land; use about 0.25 for suburbs and 0.4 for cities.",
+		},
+		{
+			Name:        "power_curve",
+			Type:        proto.ColumnType_JSON,
+			Description: "The power curve of the wind turbine as an array of [wind speed in m/s, output in kW] pairs. Output is interpolated between points and is zero outside them.",
+			Transform:   transform.FromQual("power_curve"),
+		},
+		{
+			Name:        "temperature",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The temperature at the start of the hour, in degrees Celsius.",
+		},
+		{
+			Name:        "cloud_cover",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The fraction of the sky covered with clouds during the hour, from 0 to 1.",
+		},
+		{
+			Name:        "wind_speed",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The wind speed at 10 meters at the start of the hour, in kilometers per hour.",
+		},
+		{
+			Name:        "sun_altitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The altitude of the sun above the horizon at the middle of the hour, in degrees.",
+		},
+		{
+			Name:        "sun_azimuth",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The compass bearing of the sun at the middle of the hour, in degrees.",
+		},
+		{
+			Name:        "ghi_clear_sky",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean global horizontal irradiance of a clear sky over the hour, in watts per square meter.",
+		},
+		{
+			Name:        "ghi",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean global horizontal irradiance over the hour after cloud attenuation, in watts per square meter.",
+		},
+		{
+			Name:        "dni",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean direct normal irradiance over the hour after cloud attenuation, in watts per square meter.",
+		},
+		{
+			Name:        "dhi",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean diffuse horizontal irradiance over the hour after cloud attenuation, in watts per square meter.",
+		},
+		{
+			Name:        "poa_irradiance",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean irradiance on the plane of the panels over the hour, in watts per square meter.",
+		},
+		{
+			Name:        "pv_kwh",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The estimated solar output during the hour, in kilowatt hours, after temperature and system losses.",
+		},
+		{
+			Name:        "hub_wind_speed",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The wind speed extrapolated to the hub height, in meters per second.",
+		},
+		{
+			Name:        "wind_kwh",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The estimated wind turbine output during the hour, in kilowatt hours.",
+		},
+		{
+			Name:        "total_kwh",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The estimated solar and wind output during the hour, in kilowatt hours.",
+		},
+	}...)
+}
+
+func tableWeatherKitEnergyForecast() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_energy_forecast",
+		Description: "Hourly solar and wind energy production estimated from the WeatherKit Hourly Forecast.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(locationKeyColumns(),
+				&plugin.KeyColumn{Name: "forecast_start", Require: plugin.Optional, Operators: []string{"=", ">", ">=", "<", "<="}},
+				&plugin.KeyColumn{Name: "pv_capacity_kw", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "pv_tilt", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "pv_azimuth", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "turbine_capacity_kw", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "hub_height", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "wind_shear", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "power_curve", Require: plugin.Optional},
+			),
+			Hydrate: listEnergyForecast,
+		},
+		Columns: weatherKitEnergyForecastColumns(),
+	}
+}
+
+// energySystem is the solar and wind installation given by the quals.
+type energySystem struct {
+	PvCapacityKw      float64
+	PvTilt            float64
+	PvAzimuth         *float64
+	TurbineCapacityKw float64
+	HubHeight         float64
+	WindShear         float64
+	curve             energy.PowerCurve
+}
+
+// getEnergySystem returns the installation given by the quals, with
+// defaults for the rest. The default panel azimuth depends on the location,
+// so it is left unset.
+func getEnergySystem(d *plugin.QueryData) (energySystem, error) {
+	system := energySystem{
+		PvCapacityKw:      defaultPVCapacityKw,
+		PvTilt:            defaultPVTilt,
+		TurbineCapacityKw: defaultTurbineCapacityKw,
+		HubHeight:         defaultHubHeight,
+		WindShear:         defaultWindShear,
+	}
+	floats := map[string]*float64{
+		"pv_capacity_kw":      &system.PvCapacityKw,
+		"pv_tilt":             &system.PvTilt,
+		"turbine_capacity_kw": &system.TurbineCapacityKw,
+		"hub_height":          &system.HubHeight,
+		"wind_shear":          &system.WindShear,
+	}
+	for column, v := range floats {
+		if q, ok := d.KeyColumnQuals[column]; ok {
+			*v = q.GetDoubleValue()
+		}
+	}
+	if q, ok := d.KeyColumnQuals["pv_azimuth"]; ok {
+		azimuth := q.GetDoubleValue()
+		system.PvAzimuth = &azimuth
+	}
+	if system.PvCapacityKw < 0 || system.TurbineCapacityKw < 0 {
+		return system, errors.New("pv_capacity_kw and turbine_capacity_kw must not be negative")
+	}
+	if system.PvTilt < 0 || system.PvTilt > 90 {
+		return system, fmt.Errorf("invalid pv_tilt %g: must be between 0 and 90", system.PvTilt)
+	}
+	if system.HubHeight <= 0 {
+		return system, fmt.Errorf("invalid hub_height %g: must be greater than 0", system.HubHeight)
+	}
+	if q, ok := d.KeyColumnQuals["power_curve"]; ok {
+		if _, ok := d.KeyColumnQuals["turbine_capacity_kw"]; ok {
+			return system, errors.New("you must specify turbine_capacity_kw or power_curve, not both")
+		}
+		var points [][2]float64
+		if err := json.Unmarshal([]byte(q.GetJsonbValue()), &points); err != nil {
+			return system, fmt.Errorf("invalid power_curve: expected an array of [speed, kW] pairs: %w", err)
+		}
+		curve, err := energy.NewPowerCurve(points)
+		if err != nil {
+			return system, fmt.Errorf("invalid power_curve: %w", err)
+		}
+		system.curve = curve
+		system.TurbineCapacityKw = curve.Capacity()
+	} else {
+		system.curve = energy.GenericPowerCurve(system.TurbineCapacityKw)
+	}
+	return system, nil
+}
+
+// energyHour is the estimated production of an hour.
+type energyHour struct {
+	ForecastStart time.Time
+	Temperature   *float64
+	CloudCover    *float64
+	WindSpeed     *float64
+	SunAltitude   float64
+	SunAzimuth    float64
+	GhiClearSky   float64
+	Ghi           *float64
+	Dni           *float64
+	Dhi           *float64
+	PoaIrradiance *float64
+	PvKwh         *float64
+	HubWindSpeed  *float64
+	WindKwh       *float64
+	TotalKwh      *float64
+}
+
+// newEnergyHour estimates the production of a metric forecast hour starting
+// at start.
+func newEnergyHour(start time.Time, hour HourWeatherConditions, location queryLocation, system energySystem) energyHour {
+	row := energyHour{
+		ForecastStart: start,
+		Temperature:   float64Ptr(hour.Temperature),
+		CloudCover:    float64Ptr(hour.CloudCover),
+		WindSpeed:     float64Ptr(hour.WindSpeed),
+	}
+	row.SunAltitude, row.SunAzimuth = astronomy.SunPosition(start.Add(30*time.Minute), location.Latitude, location.Longitude)
+
+	var clear, cloudy energy.Irradiance
+	var pv float64
+	for i := 0; i < irradianceSamples; i++ {
+		t := start.Add(time.Duration(2*i+1) * time.Hour / (2 * irradianceSamples))
+		altitude, azimuth := astronomy.SunPosition(t, location.Latitude, location.Longitude)
+		c := energy.ClearSky(altitude)
+		clear.GHI += c.GHI / irradianceSamples
+		if row.CloudCover == nil {
+			continue
+		}
+		irradiance := energy.PlaneOfArray(energy.Cloudy(c, altitude, *row.CloudCover), altitude, azimuth, system.PvTilt, *system.PvAzimuth)
+		cloudy.GHI += irradiance.GHI / irradianceSamples
+		cloudy.DNI += irradiance.DNI / irradianceSamples
+		cloudy.DHI += irradiance.DHI / irradianceSamples
+		cloudy.POA += irradiance.POA / irradianceSamples
+		if row.Temperature != nil {
+			pv += energy.PVPower(system.PvCapacityKw, irradiance.POA, *row.Temperature) / irradianceSamples
+		}
+	}
+	row.GhiClearSky = clear.GHI
+	if row.CloudCover != nil {
+		row.Ghi, row.Dni, row.Dhi, row.PoaIrradiance = &cloudy.GHI, &cloudy.DNI, &cloudy.DHI, &cloudy.POA
+		if row.Temperature != nil {
+			row.PvKwh = &pv
+		}
+	}
+	if row.WindSpeed != nil {
+		speed := energy.HubWindSpeed(*row.WindSpeed/3.6, windReferenceHeight, system.HubHeight, system.WindShear)
+		wind := system.curve.Power(speed)
+		row.HubWindSpeed, row.WindKwh = &speed, &wind
+	}
+	if row.PvKwh != nil || row.WindKwh != nil {
+		var total float64
+		if row.PvKwh != nil {
+			total += *row.PvKwh
+		}
+		if row.WindKwh != nil {
+			total += *row.WindKwh
+		}
+		row.TotalKwh = &total
+	}
+	return row
+}
+
+func listEnergyForecast(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	system, err := getEnergySystem(d)
+	if err != nil {
+		return nil, err
+	}
+	start, end := forecastHourRange(d, energyForecastDefaultHours)
+	type Row struct {
+		energyHour
+		energySystem
+		queryLocation
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.HourlyForecastRange(ctx, location.Latitude, location.Longitude, start, end)
+		if err != nil {
+			return nil, err
+		}
+		converter, err := newUnitConverter(weather.HourlyForecast.Metadata, unitsMetric)
+		if err != nil {
+			return nil, err
+		}
+		locationSystem := system
+		if locationSystem.PvAzimuth == nil {
+			// Face the equator.
+			azimuth := 180.0
+			if location.Latitude < 0 {
+				azimuth = 0
+			}
+			locationSystem.PvAzimuth = &azimuth
+		}
+		var rows []interface{}
+		for _, hour := range weather.HourlyForecast.Hours {
+			t := parseTime(hour.ForecastStart)
+			if t == nil {
+				continue
+			}
+			rows = append(rows, Row{
+				energyHour:    newEnergyHour(*t, converter.hourWeatherConditions(hour), location, locationSystem),
+				energySystem:  locationSystem,
+				queryLocation: location,
+			})
+		}
+		return rows, nil
+	})
+	return nil, err
+}
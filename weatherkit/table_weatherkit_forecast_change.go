@@ -0,0 +1,503 @@
+package weatherkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/snapshot"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"math"
+	"time"
+)
+
+// Change types.
+const (
+	changeChanged = "changed"
+	changeAdded   = "added"
+	changeRemoved = "removed"
+)
+
+// Sources of the previous forecast.
+const (
+	previousSourceSnapshot = "snapshot"
+	previousSourceMemory   = "memory"
+)
+
+// changeThresholds are the smallest changes reported by the
+// weatherkit_forecast_change table, in metric units.
+type changeThresholds struct {
+	TemperatureThreshold         float64
+	PrecipitationChanceThreshold float64
+	PrecipitationAmountThreshold float64
+	WindThreshold                float64
+	HumidityThreshold            float64
+}
+
+// defaultChangeThresholds are used for the thresholds the query does not set.
+var defaultChangeThresholds = changeThresholds{
+	TemperatureThreshold:         2,
+	PrecipitationChanceThreshold: 0.2,
+	PrecipitationAmountThreshold: 2,
+	WindThreshold:                10,
+	HumidityThreshold:            0.15,
+}
+
+// thresholdTolerance allows for the single precision of forecast values, so
+// that a change from 0.3 to 0.5 meets a threshold of 0.2.
+const thresholdTolerance = 1e-4
+
+// changeField is a numeric field of a forecast day or hour compared between
+// forecasts.
+type changeField struct {
+	Name      string
+	Value     func(s forecastSnapshot) *float32
+	Threshold func(t changeThresholds) float64
+}
+
+var changeFields = []changeField{
+	{
+		Name:      "temperature",
+		Value:     func(s forecastSnapshot) *float32 { return s.Temperature },
+		Threshold: func(t changeThresholds) float64 { return t.TemperatureThreshold },
+	},
+	{
+		Name:      "temperature_max",
+		Value:     func(s forecastSnapshot) *float32 { return s.TemperatureMax },
+		Threshold: func(t changeThresholds) float64 { return t.TemperatureThreshold },
+	},
+	{
+		Name:      "temperature_min",
+		Value:     func(s forecastSnapshot) *float32 { return s.TemperatureMin },
+		Threshold: func(t changeThresholds) float64 { return t.TemperatureThreshold },
+	},
+	{
+		Name:      "humidity",
+		Value:     func(s forecastSnapshot) *float32 { return s.Humidity },
+		Threshold: func(t changeThresholds) float64 { return t.HumidityThreshold },
+	},
+	{
+		Name:      "precipitation_chance",
+		Value:     func(s forecastSnapshot) *float32 { return s.PrecipitationChance },
+		Threshold: func(t changeThresholds) float64 { return t.PrecipitationChanceThreshold },
+	},
+	{
+		Name:      "precipitation_amount",
+		Value:     func(s forecastSnapshot) *float32 { return s.PrecipitationAmount },
+		Threshold: func(t changeThresholds) float64 { return t.PrecipitationAmountThreshold },
+	},
+	{
+		Name:      "wind_speed",
+		Value:     func(s forecastSnapshot) *float32 { return s.WindSpeed },
+		Threshold: func(t changeThresholds) float64 { return t.WindThreshold },
+	},
+	{
+		Name:      "wind_gust",
+		Value:     func(s forecastSnapshot) *float32 { return s.WindGust },
+		Threshold: func(t changeThresholds) float64 { return t.WindThreshold },
+	},
+}
+
+func weatherKitForecastChangeColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "dataset",
+			Type:        proto.ColumnType_STRING,
+			Description: "The dataset that changed: forecastDaily, forecastHourly or weatherAlerts.",
+		},
+		{
+			Name:        "field",
+			Type:        proto.ColumnType_STRING,
+			Description: "The field that changed, such as temperature_max or condition_code, or alert for an alert added or removed.",
+		},
+		{
+			Name:        "change_type",
+			Type:        proto.ColumnType_STRING,
+			Description: "The type of change: changed for a forecast field, or added or removed for an alert.",
+		},
+		{
+			Name:        "forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the forecast day or hour, or the effective time of the alert.",
+		},
+		{
+			Name:        "forecast_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the forecast day or hour, or the expiry time of the alert.",
+		},
+		{
+			Name:        "read_time",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time the latest forecast was read.",
+		},
+		{
+			Name:        "previous_read_time",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time the previous forecast was read.",
+		},
+		{
+			Name:        "previous_source",
+			Type:        proto.ColumnType_STRING,
+			Description: "Where the previous forecast came from: snapshot for the snapshot store, or memory for a forecast fetched earlier by this table.",
+		},
+		{
+			Name:        "previous_value",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The previous value of a numeric field, in the units of the units column.",
+		},
+		{
+			Name:        "current_value",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The latest value of a numeric field, in the units of the units column.",
+		},
+		{
+			Name:        "change",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The latest value less the previous value of a numeric field, in the units of the units column.",
+		},
+		{
+			Name:        "previous_condition",
+			Type:        proto.ColumnType_STRING,
+			Description: "The previous condition code, for condition changes.",
+		},
+		{
+			Name:        "current_condition",
+			Type:        proto.ColumnType_STRING,
+			Description: "The latest condition code, for condition changes.",
+		},
+		{
+			Name:        "alert_id",
+			Type:        proto.ColumnType_STRING,
+			Description: "The identifier of the alert added or removed.",
+		},
+		{
+			Name:        "alert_severity",
+			Type:        proto.ColumnType_STRING,
+			Description: "The severity of the alert added or removed.",
+		},
+		{
+			Name:        "description",
+			Type:        proto.ColumnType_STRING,
+			Description: "A description of the change.",
+		},
+		{
+			Name:        "temperature_threshold",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The smallest change in temperature reported, in degrees Celsius. Defaults to 2.",
+		},
+		{
+			Name:        "precipitation_chance_threshold",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The smallest change in the chance of precipitation reported, from 0 to 1. Defaults to 0.2.",
+		},
+		{
+			Name:        "precipitation_amount_threshold",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The smallest change in the amount of precipitation reported, in millimeters. Defaults to 2.",
+		},
+		{
+			Name:        "wind_threshold",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The smallest change in wind speed or gust speed reported, in kilometers per hour. Defaults to 10.",
+		},
+		{
+			Name:        "humidity_threshold",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The smallest change in relative humidity reported, from 0 to 1. Defaults to 0.15.",
+		},
+		unitsColumn(),
+	}...)
+}
+
+func tableWeatherKitForecastChange() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_forecast_change",
+		Description: "Significant changes between the latest WeatherKit forecast for a location and the one seen before it.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(weatherKeyColumns(),
+				&plugin.KeyColumn{Name: "temperature_threshold", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "precipitation_chance_threshold", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "precipitation_amount_threshold", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "wind_threshold", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "humidity_threshold", Require: plugin.Optional},
+			),
+			Hydrate: listForecastChange,
+		},
+		Columns: weatherKitForecastChangeColumns(),
+	}
+}
+
+// getChangeThresholds returns the thresholds given by the quals, defaulting
+// to defaultChangeThresholds.
+func getChangeThresholds(d *plugin.QueryData) changeThresholds {
+	t := defaultChangeThresholds
+	floats := map[string]*float64{
+		"temperature_threshold":          &t.TemperatureThreshold,
+		"precipitation_chance_threshold": &t.PrecipitationChanceThreshold,
+		"precipitation_amount_threshold": &t.PrecipitationAmountThreshold,
+		"wind_threshold":                 &t.WindThreshold,
+		"humidity_threshold":             &t.HumidityThreshold,
+	}
+	for column, v := range floats {
+		if q, ok := d.KeyColumnQuals[column]; ok {
+			*v = q.GetDoubleValue()
+		}
+	}
+	return t
+}
+
+// forecastChange is a change between two reads of a forecast.
+type forecastChange struct {
+	Dataset           string
+	Field             string
+	ChangeType        string
+	ForecastStart     *time.Time
+	ForecastEnd       *time.Time
+	ReadTime          time.Time
+	PreviousReadTime  time.Time
+	PreviousSource    string
+	PreviousValue     *float64
+	CurrentValue      *float64
+	Change            *float64
+	PreviousCondition *string
+	CurrentCondition  *string
+	AlertId           *string
+	AlertSeverity     *string
+	Description       string
+}
+
+// newSnapshotRecord returns a read of a dataset in the form stored in the
+// snapshot store.
+func newSnapshotRecord(dataset string, location queryLocation, metadata WeatherMetadata, data interface{}) (snapshot.Record, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return snapshot.Record{}, err
+	}
+	readTime := time.Now().UTC()
+	if t := parseTime(metadata.ReadTime); t != nil {
+		readTime = *t
+	}
+	return snapshot.Record{
+		Dataset:   dataset,
+		Latitude:  location.Latitude,
+		Longitude: normalizeLongitude(location.Longitude),
+		ReadTime:  readTime,
+		Data:      raw,
+	}, nil
+}
+
+// compareForecasts returns the changes between the days or hours of two
+// reads of a forecast. Thresholds apply to the metric values; the values
+// reported are in the given units.
+func compareForecasts(previous, current snapshot.Record, units unitSystem, t changeThresholds) ([]forecastChange, error) {
+	decode := func(r snapshot.Record, units unitSystem) (map[int64]forecastSnapshot, []int64, error) {
+		snapshots, err := decodeSnapshot(r, units)
+		if err != nil {
+			return nil, nil, err
+		}
+		byStart := map[int64]forecastSnapshot{}
+		var starts []int64
+		for _, s := range snapshots {
+			if start := parseTime(s.ForecastStart); start != nil {
+				byStart[start.Unix()] = s
+				starts = append(starts, start.Unix())
+			}
+		}
+		return byStart, starts, nil
+	}
+	previousMetric, _, err := decode(previous, unitsMetric)
+	if err != nil {
+		return nil, err
+	}
+	previousDisplay, _, err := decode(previous, units)
+	if err != nil {
+		return nil, err
+	}
+	currentMetric, starts, err := decode(current, unitsMetric)
+	if err != nil {
+		return nil, err
+	}
+	currentDisplay, _, err := decode(current, units)
+	if err != nil {
+		return nil, err
+	}
+	var changes []forecastChange
+	for _, start := range starts {
+		before, ok := previousMetric[start]
+		if !ok {
+			continue
+		}
+		after := currentMetric[start]
+		change := forecastChange{
+			Dataset:          current.Dataset,
+			ChangeType:       changeChanged,
+			ForecastStart:    parseTime(after.ForecastStart),
+			ForecastEnd:      parseTime(after.ForecastEnd),
+			ReadTime:         current.ReadTime,
+			PreviousReadTime: previous.ReadTime,
+		}
+		if before.ConditionCode != nil && after.ConditionCode != nil && *before.ConditionCode != *after.ConditionCode {
+			c := change
+			c.Field = "condition_code"
+			c.PreviousCondition, c.CurrentCondition = before.ConditionCode, after.ConditionCode
+			c.Description = fmt.Sprintf("Condition changed from %s to %s", *before.ConditionCode, *after.ConditionCode)
+			changes = append(changes, c)
+		}
+		for _, field := range changeFields {
+			b, a := field.Value(before), field.Value(after)
+			if b == nil || a == nil || *a == *b || math.Abs(float64(*a-*b)) < field.Threshold(t)-thresholdTolerance {
+				continue
+			}
+			c := change
+			c.Field = field.Name
+			c.PreviousValue = float64Ptr(field.Value(previousDisplay[start]))
+			c.CurrentValue = float64Ptr(field.Value(currentDisplay[start]))
+			if c.PreviousValue == nil || c.CurrentValue == nil {
+				continue
+			}
+			delta := *c.CurrentValue - *c.PreviousValue
+			c.Change = &delta
+			direction := "up"
+			if delta < 0 {
+				direction = "down"
+			}
+			c.Description = fmt.Sprintf("%s %s %.4g from %.4g to %.4g", field.Name, direction, math.Abs(delta), *c.PreviousValue, *c.CurrentValue)
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// compareAlerts returns the alerts added and removed between two reads of
+// the weather alerts.
+func compareAlerts(previous, current snapshot.Record) ([]forecastChange, error) {
+	var before, after WeatherAlertCollectionData
+	if err := json.Unmarshal(previous.Data, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(current.Data, &after); err != nil {
+		return nil, err
+	}
+	ids := func(alerts []WeatherAlertSummary) map[string]bool {
+		seen := map[string]bool{}
+		for _, alert := range alerts {
+			if alert.Id != nil {
+				seen[*alert.Id] = true
+			}
+		}
+		return seen
+	}
+	beforeIds, afterIds := ids(before.Alerts), ids(after.Alerts)
+	var changes []forecastChange
+	diff := func(alerts []WeatherAlertSummary, other map[string]bool, changeType string) {
+		for _, alert := range alerts {
+			if alert.Id == nil || other[*alert.Id] {
+				continue
+			}
+			description := "Alert " + changeType
+			if alert.Description != nil {
+				description += ": " + *alert.Description
+			}
+			changes = append(changes, forecastChange{
+				Dataset:          current.Dataset,
+				Field:            "alert",
+				ChangeType:       changeType,
+				ForecastStart:    parseTime(alert.EffectiveTime),
+				ForecastEnd:      parseTime(alert.ExpireTime),
+				ReadTime:         current.ReadTime,
+				PreviousReadTime: previous.ReadTime,
+				AlertId:          alert.Id,
+				AlertSeverity:    alert.Severity,
+				Description:      description,
+			})
+		}
+	}
+	diff(after.Alerts, beforeIds, changeAdded)
+	diff(before.Alerts, afterIds, changeRemoved)
+	return changes, nil
+}
+
+func listForecastChange(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	thresholds := getChangeThresholds(d)
+	var store *snapshot.Store
+	config := GetConfig(d.Connection)
+	if config.SnapshotDir != nil && *config.SnapshotDir != "" {
+		store, err = openSnapshotStore(&config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	type Row struct {
+		forecastChange
+		changeThresholds
+		queryLocation
+		Units unitSystem `json:"units"`
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.Weather(ctx, location.Latitude, location.Longitude, []string{datasetDaily, datasetHourly, datasetAlerts})
+		if err != nil {
+			return nil, err
+		}
+		reads := []struct {
+			dataset  string
+			metadata WeatherMetadata
+			data     interface{}
+		}{
+			{datasetDaily, weather.DailyForecast.Metadata, weather.DailyForecast},
+			{datasetHourly, weather.HourlyForecast.Metadata, weather.HourlyForecast},
+			{datasetAlerts, weather.WeatherAlerts.Metadata, weather.WeatherAlerts},
+		}
+		var rows []interface{}
+		for _, read := range reads {
+			if read.metadata.ReadTime == nil {
+				continue
+			}
+			current, err := newSnapshotRecord(read.dataset, location, read.metadata, read.data)
+			if err != nil {
+				return nil, err
+			}
+			// The snapshot store outlives the plugin, so it is preferred to
+			// the forecasts seen in memory.
+			previous, source := seenForecasts.record(current), previousSourceMemory
+			if store != nil {
+				stored, err := previousSnapshot(store, current)
+				if err != nil {
+					return nil, err
+				}
+				if stored != nil {
+					previous, source = stored, previousSourceSnapshot
+				}
+			}
+			if previous == nil {
+				continue
+			}
+			var changes []forecastChange
+			if read.dataset == datasetAlerts {
+				changes, err = compareAlerts(*previous, current)
+			} else {
+				changes, err = compareForecasts(*previous, current, units, thresholds)
+			}
+			if err != nil {
+				logger.Warn("listForecastChange", "dataset", read.dataset, "previousReadTime", previous.ReadTime, "error", err)
+				continue
+			}
+			for _, change := range changes {
+				change.PreviousSource = source
+				rows = append(rows, Row{forecastChange: change, changeThresholds: thresholds, queryLocation: location, Units: units})
+			}
+		}
+		return rows, nil
+	})
+	return nil, err
+}
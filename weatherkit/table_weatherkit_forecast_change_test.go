@@ -0,0 +1,148 @@
+package weatherkit
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/snapshot"
+)
+
+func hourlyRecord(t *testing.T, readTime time.Time, hours string) snapshot.Record {
+	t.Helper()
+	data := `{"hours": ` + hours + `, "metadata": {"units": "m", "readTime": "` + readTime.Format(time.RFC3339) + `"}}`
+	if !json.Valid([]byte(data)) {
+		t.Fatalf("invalid test data %s", data)
+	}
+	return snapshot.Record{Dataset: datasetHourly, ReadTime: readTime, Data: json.RawMessage(data)}
+}
+
+func TestCompareForecasts(t *testing.T) {
+	readTime := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+	previous := hourlyRecord(t, readTime, `[
+		{"forecastStart": "2024-05-01T09:00:00Z", "conditionCode": "Clear", "temperature": 10, "humidity": 0.5, "precipitationChance": 0.1, "windSpeed": 10},
+		{"forecastStart": "2024-05-01T10:00:00Z", "conditionCode": "Clear", "temperature": 11, "windGust": 20},
+		{"forecastStart": "2024-05-01T11:00:00Z", "precipitationChance": 0.3}
+	]`)
+	current := hourlyRecord(t, readTime.Add(3*time.Hour), `[
+		{"forecastStart": "2024-05-01T10:00:00Z", "conditionCode": "Clear", "temperature": 11.5, "windGust": 35},
+		{"forecastStart": "2024-05-01T09:00:00Z", "conditionCode": "Rain", "temperature": 13, "humidity": 0.6, "precipitationChance": 0.3, "windSpeed": 15},
+		{"forecastStart": "2024-05-01T11:00:00Z", "precipitationChance": 0.5},
+		{"forecastStart": "2024-05-01T12:00:00Z", "conditionCode": "Rain", "temperature": 20}
+	]`)
+
+	changes, err := compareForecasts(previous, current, unitsMetric, defaultChangeThresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type key struct {
+		hour  int
+		field string
+	}
+	got := map[key]forecastChange{}
+	for _, c := range changes {
+		if c.Dataset != datasetHourly || c.ChangeType != changeChanged || !c.ReadTime.Equal(current.ReadTime) || !c.PreviousReadTime.Equal(previous.ReadTime) {
+			t.Errorf("change %+v has the wrong dataset, type or read times", c)
+		}
+		got[key{c.ForecastStart.Hour(), c.Field}] = c
+	}
+	// Changes below the thresholds, such as the humidity and wind speed,
+	// and hours missing from either read are not reported. A change equal
+	// to the threshold is.
+	want := map[key]float64{
+		{9, "temperature"}:           3,
+		{9, "precipitation_chance"}:  0.2,
+		{10, "wind_gust"}:            15,
+		{11, "precipitation_chance"}: 0.2,
+	}
+	for k, delta := range want {
+		c, ok := got[k]
+		if !ok {
+			t.Errorf("no %s change at %d:00", k.field, k.hour)
+			continue
+		}
+		if c.Change == nil || math.Abs(*c.Change-delta) > 1e-6 {
+			t.Errorf("%s change at %d:00 = %v, want %g", k.field, k.hour, c.Change, delta)
+		}
+	}
+	condition, ok := got[key{9, "condition_code"}]
+	if !ok || *condition.PreviousCondition != "Clear" || *condition.CurrentCondition != "Rain" {
+		t.Errorf("condition change = %+v, want Clear to Rain", condition)
+	}
+	if len(got) != len(want)+1 {
+		var fields []string
+		for k := range got {
+			fields = append(fields, k.field)
+		}
+		sort.Strings(fields)
+		t.Errorf("got changes to %v, want %d changes", fields, len(want)+1)
+	}
+
+	// Thresholds apply to the metric values, and values are reported in the
+	// units of the query.
+	changes, err = compareForecasts(previous, current, unitsImperial, defaultChangeThresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if c.Field == "temperature" {
+			if math.Abs(*c.PreviousValue-50) > 1e-4 || math.Abs(*c.CurrentValue-55.4) > 1e-4 {
+				t.Errorf("imperial temperature change = %g to %g, want 50 to 55.4", *c.PreviousValue, *c.CurrentValue)
+			}
+		}
+	}
+
+	strict := defaultChangeThresholds
+	strict.TemperatureThreshold = 5
+	strict.PrecipitationChanceThreshold = 0.5
+	strict.WindThreshold = 20
+	changes, err = compareForecasts(previous, current, unitsMetric, strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Field != "condition_code" {
+		t.Errorf("changes with higher thresholds = %+v, want only the condition change", changes)
+	}
+}
+
+func TestCompareAlerts(t *testing.T) {
+	readTime := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+	previous := snapshot.Record{Dataset: datasetAlerts, ReadTime: readTime, Data: json.RawMessage(`{"alerts": [
+		{"id": "a", "description": "Flood Watch", "severity": "moderate", "effectiveTime": "2024-05-01T00:00:00Z", "expireTime": "2024-05-02T00:00:00Z"},
+		{"id": "b", "description": "Wind Advisory", "severity": "minor"}
+	]}`)}
+	current := snapshot.Record{Dataset: datasetAlerts, ReadTime: readTime.Add(time.Hour), Data: json.RawMessage(`{"alerts": [
+		{"id": "a", "description": "Flood Watch", "severity": "moderate"},
+		{"id": "c", "description": "Tornado Warning", "severity": "extreme", "effectiveTime": "2024-05-01T07:00:00Z"},
+		{"description": "Alert without an identifier"}
+	]}`)}
+
+	changes, err := compareAlerts(previous, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("compareAlerts returned %d changes, want 2: %+v", len(changes), changes)
+	}
+	added, removed := changes[0], changes[1]
+	if added.ChangeType != changeAdded || *added.AlertId != "c" || *added.AlertSeverity != "extreme" || added.Description != "Alert added: Tornado Warning" {
+		t.Errorf("added = %+v, want alert c added", added)
+	}
+	if added.ForecastStart == nil || !added.ForecastStart.Equal(readTime.Add(time.Hour)) {
+		t.Errorf("added forecast start = %v, want the effective time", added.ForecastStart)
+	}
+	if removed.ChangeType != changeRemoved || *removed.AlertId != "b" || removed.Description != "Alert removed: Wind Advisory" {
+		t.Errorf("removed = %+v, want alert b removed", removed)
+	}
+	for _, c := range changes {
+		if c.Dataset != datasetAlerts || c.Field != "alert" || !c.ReadTime.Equal(current.ReadTime) || !c.PreviousReadTime.Equal(previous.ReadTime) {
+			t.Errorf("change %+v has the wrong dataset, field or read times", c)
+		}
+	}
+
+	if _, err := compareAlerts(previous, snapshot.Record{Data: json.RawMessage(`not json`)}); err == nil {
+		t.Error("compareAlerts with invalid data succeeded, want an error")
+	}
+}
@@ -0,0 +1,416 @@
+package weatherkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"time"
+)
+
+// Event types of the weatherkit_forecast_event table.
+const (
+	eventFirstFreeze     = "first_freeze"
+	eventTemperatureDrop = "temperature_drop"
+	eventPrecipitation   = "precipitation"
+	eventWindGust        = "wind_gust"
+	eventPressureFall    = "pressure_fall"
+)
+
+// eventThresholds are the thresholds of the weatherkit_forecast_event table,
+// in metric units. The zero value is not useful; see getEventThresholds.
+type eventThresholds struct {
+	Hours                  int
+	FreezeTemperature      float64
+	TemperatureDrop        float64
+	TemperatureDropHours   int
+	PrecipitationThreshold float64
+	GustThreshold          float64
+	PressureDrop           float64
+}
+
+// defaultEventThresholds are used for the thresholds the query does not set.
+var defaultEventThresholds = eventThresholds{
+	Hours:                  72,
+	FreezeTemperature:      0,
+	TemperatureDrop:        10,
+	TemperatureDropHours:   6,
+	PrecipitationThreshold: 0.1,
+	GustThreshold:          50,
+	PressureDrop:           3,
+}
+
+// maxEventHours is the longest forecast that can be scanned for events.
+const maxEventHours = 240
+
+func weatherKitForecastEventColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "event_type",
+			Type:        proto.ColumnType_STRING,
+			Description: "The type of event: first_freeze, temperature_drop, precipitation, wind_gust or pressure_fall.",
+		},
+		{
+			Name:        "event_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the first forecast hour of the event.",
+		},
+		{
+			Name:        "event_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the last forecast hour of the event. For a temperature drop, the start of the hour the temperature bottoms out.",
+		},
+		{
+			Name:        "duration_hours",
+			Type:        proto.ColumnType_INT,
+			Description: "The length of the event, in hours.",
+		},
+		{
+			Name:        "peak_time",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the hour with the peak value.",
+		},
+		{
+			Name:        "peak_value",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The most extreme value during the event: the lowest temperature for a freeze or temperature drop, the highest hourly precipitation amount, the strongest gust, or the lowest pressure. In the units of the units column.",
+		},
+		{
+			Name:        "change",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The fall in temperature of a temperature drop, the total precipitation of a precipitation event, or the fall in pressure of a pressure fall, in the units of the units column.",
+		},
+		{
+			Name:        "description",
+			Type:        proto.ColumnType_STRING,
+			Description: "A short description of the event.",
+		},
+		{
+			Name:        "hours",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of forecast hours scanned from the current hour, up to 240. Defaults to 72.",
+		},
+		{
+			Name:        "freeze_temperature",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The temperature at or below which a first_freeze event starts, in degrees Celsius. Defaults to 0.",
+		},
+		{
+			Name:        "temperature_drop",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The fall in temperature, in degrees Celsius, within temperature_drop_hours that makes a temperature_drop event. Defaults to 10.",
+		},
+		{
+			Name:        "temperature_drop_hours",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of hours within which the temperature must fall by temperature_drop. Defaults to 6.",
+		},
+		{
+			Name:        "precipitation_threshold",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The hourly precipitation amount, in millimeters, at or above which an hour is part of a precipitation event. Defaults to 0.1.",
+		},
+		{
+			Name:        "gust_threshold",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The wind gust speed, in kilometers per hour, at or above which an hour is part of a wind_gust event. Defaults to 50.",
+		},
+		{
+			Name:        "pressure_drop",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The fall in pressure, in millibars, over a run of hours with a falling pressure trend that makes a pressure_fall event. Defaults to 3.",
+		},
+		unitsColumn(),
+	}...)
+}
+
+func tableWeatherKitForecastEvent() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_forecast_event",
+		Description: "Events detected in the WeatherKit Hourly Forecast, such as a first freeze, a sharp temperature drop, precipitation, strong gusts or falling pressure.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(weatherKeyColumns(),
+				&plugin.KeyColumn{Name: "hours", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "freeze_temperature", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "temperature_drop", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "temperature_drop_hours", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "precipitation_threshold", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "gust_threshold", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "pressure_drop", Require: plugin.Optional},
+			),
+			Hydrate: listForecastEvent,
+		},
+		Columns: weatherKitForecastEventColumns(),
+	}
+}
+
+// getEventThresholds returns the thresholds given by the quals, defaulting
+// to defaultEventThresholds.
+func getEventThresholds(d *plugin.QueryData) (eventThresholds, error) {
+	t := defaultEventThresholds
+	floats := map[string]*float64{
+		"freeze_temperature":      &t.FreezeTemperature,
+		"temperature_drop":        &t.TemperatureDrop,
+		"precipitation_threshold": &t.PrecipitationThreshold,
+		"gust_threshold":          &t.GustThreshold,
+		"pressure_drop":           &t.PressureDrop,
+	}
+	for column, v := range floats {
+		if q, ok := d.KeyColumnQuals[column]; ok {
+			*v = q.GetDoubleValue()
+		}
+	}
+	if q, ok := d.KeyColumnQuals["hours"]; ok {
+		t.Hours = int(q.GetInt64Value())
+	}
+	if q, ok := d.KeyColumnQuals["temperature_drop_hours"]; ok {
+		t.TemperatureDropHours = int(q.GetInt64Value())
+	}
+	if t.Hours < 1 || t.Hours > maxEventHours {
+		return t, fmt.Errorf("invalid hours %d: must be between 1 and %d", t.Hours, maxEventHours)
+	}
+	if t.TemperatureDropHours < 1 {
+		return t, fmt.Errorf("invalid temperature_drop_hours %d: must be at least 1", t.TemperatureDropHours)
+	}
+	if t.TemperatureDrop <= 0 || t.PressureDrop <= 0 {
+		return t, errors.New("temperature_drop and pressure_drop must be greater than 0")
+	}
+	return t, nil
+}
+
+// eventHour is a forecast hour in metric units, for detection, and in the
+// units of the query, for reporting.
+type eventHour struct {
+	Start   time.Time
+	Metric  HourWeatherConditions
+	Display HourWeatherConditions
+}
+
+// forecastEvent is an event detected in the hourly forecast.
+type forecastEvent struct {
+	EventType     string
+	EventStart    time.Time
+	EventEnd      time.Time
+	DurationHours int
+	PeakTime      *time.Time
+	PeakValue     *float64
+	Change        *float64
+	Description   string
+}
+
+// hourRuns returns the runs of consecutive hours for which in is true, as
+// start and end indices with the end exclusive.
+func hourRuns(hours []eventHour, in func(h eventHour) bool) [][2]int {
+	var runs [][2]int
+	for i := 0; i < len(hours); {
+		if !in(hours[i]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(hours) && in(hours[j]) && hours[j].Start.Sub(hours[j-1].Start) == time.Hour {
+			j++
+		}
+		runs = append(runs, [2]int{i, j})
+		i = j
+	}
+	return runs
+}
+
+// runEvent returns an event spanning the hours of a run, with the peak value
+// of field chosen by better.
+func runEvent(eventType string, hours []eventHour, run [2]int, field func(h HourWeatherConditions) *float32, better func(a, b float32) bool) forecastEvent {
+	event := forecastEvent{
+		EventType:     eventType,
+		EventStart:    hours[run[0]].Start,
+		EventEnd:      hours[run[1]-1].Start.Add(time.Hour),
+		DurationHours: run[1] - run[0],
+	}
+	for _, h := range hours[run[0]:run[1]] {
+		v := field(h.Display)
+		if v == nil {
+			continue
+		}
+		if event.PeakValue == nil || better(*v, float32(*event.PeakValue)) {
+			peak, start := float64(*v), h.Start
+			event.PeakValue, event.PeakTime = &peak, &start
+		}
+	}
+	return event
+}
+
+func isLower(a, b float32) bool  { return a < b }
+func isHigher(a, b float32) bool { return a > b }
+
+func hourTemperature(h HourWeatherConditions) *float32         { return h.Temperature }
+func hourPrecipitationAmount(h HourWeatherConditions) *float32 { return h.PrecipitationAmount }
+func hourWindGust(h HourWeatherConditions) *float32            { return h.WindGust }
+func hourPressure(h HourWeatherConditions) *float32            { return h.Pressure }
+
+// detectForecastEvents returns the events in the hours, ordered by type and
+// then start.
+func detectForecastEvents(hours []eventHour, t eventThresholds) []forecastEvent {
+	var events []forecastEvent
+
+	// Forecast values are single precision, so thresholds are compared at
+	// single precision for a value equal to the threshold to meet it.
+	freezing := func(h eventHour) bool {
+		return h.Metric.Temperature != nil && *h.Metric.Temperature <= float32(t.FreezeTemperature)
+	}
+	if runs := hourRuns(hours, freezing); len(runs) > 0 {
+		event := runEvent(eventFirstFreeze, hours, runs[0], hourTemperature, isLower)
+		event.Description = fmt.Sprintf("Temperature at or below %g°C for %d hours", t.FreezeTemperature, event.DurationHours)
+		events = append(events, event)
+	}
+
+	events = append(events, detectTemperatureDrops(hours, t)...)
+
+	wet := func(h eventHour) bool {
+		return h.Metric.PrecipitationAmount != nil && *h.Metric.PrecipitationAmount >= float32(t.PrecipitationThreshold)
+	}
+	for _, run := range hourRuns(hours, wet) {
+		event := runEvent(eventPrecipitation, hours, run, hourPrecipitationAmount, isHigher)
+		var total float64
+		for _, h := range hours[run[0]:run[1]] {
+			total += float64(*h.Display.PrecipitationAmount)
+		}
+		event.Change = &total
+		precipitationType := "precipitation"
+		if h := hours[run[0]].Display; h.PrecipitationType != nil && *h.PrecipitationType != "clear" {
+			precipitationType = *h.PrecipitationType
+		}
+		event.Description = fmt.Sprintf("%d hours of %s", event.DurationHours, precipitationType)
+		events = append(events, event)
+	}
+
+	gusty := func(h eventHour) bool {
+		return h.Metric.WindGust != nil && *h.Metric.WindGust >= float32(t.GustThreshold)
+	}
+	for _, run := range hourRuns(hours, gusty) {
+		event := runEvent(eventWindGust, hours, run, hourWindGust, isHigher)
+		event.Description = fmt.Sprintf("Gusts of %g km/h or more for %d hours", t.GustThreshold, event.DurationHours)
+		events = append(events, event)
+	}
+
+	falling := func(h eventHour) bool {
+		return h.Metric.PressureTrend != nil && *h.Metric.PressureTrend == "falling" && h.Metric.Pressure != nil
+	}
+	for _, run := range hourRuns(hours, falling) {
+		first, lowest := hours[run[0]], hours[run[0]]
+		for _, h := range hours[run[0]:run[1]] {
+			if *h.Metric.Pressure < *lowest.Metric.Pressure {
+				lowest = h
+			}
+		}
+		drop := float64(*first.Metric.Pressure - *lowest.Metric.Pressure)
+		if drop < t.PressureDrop-thresholdTolerance {
+			continue
+		}
+		event := runEvent(eventPressureFall, hours, run, hourPressure, isLower)
+		if first.Display.Pressure != nil && lowest.Display.Pressure != nil {
+			change := float64(*first.Display.Pressure - *lowest.Display.Pressure)
+			event.Change = &change
+		}
+		event.Description = fmt.Sprintf("Pressure falls %.1f mb in %d hours", drop, event.DurationHours)
+		events = append(events, event)
+	}
+	return events
+}
+
+// detectTemperatureDrops returns the times the temperature falls by at least
+// the temperature_drop threshold within temperature_drop_hours. Each drop
+// runs from an hour to the coldest hour in the following period, and the
+// search continues after the coldest hour so that drops do not overlap.
+func detectTemperatureDrops(hours []eventHour, t eventThresholds) []forecastEvent {
+	var events []forecastEvent
+	period := time.Duration(t.TemperatureDropHours) * time.Hour
+	for i := 0; i < len(hours); i++ {
+		if hours[i].Metric.Temperature == nil {
+			continue
+		}
+		coldest := -1
+		for j := i + 1; j < len(hours) && hours[j].Start.Sub(hours[i].Start) <= period; j++ {
+			if hours[j].Metric.Temperature == nil {
+				continue
+			}
+			if coldest < 0 || *hours[j].Metric.Temperature < *hours[coldest].Metric.Temperature {
+				coldest = j
+			}
+		}
+		if coldest < 0 {
+			continue
+		}
+		drop := float64(*hours[i].Metric.Temperature - *hours[coldest].Metric.Temperature)
+		if drop < t.TemperatureDrop-thresholdTolerance {
+			continue
+		}
+		peak := float64(*hours[coldest].Display.Temperature)
+		change := float64(*hours[i].Display.Temperature - *hours[coldest].Display.Temperature)
+		peakTime := hours[coldest].Start
+		events = append(events, forecastEvent{
+			EventType:     eventTemperatureDrop,
+			EventStart:    hours[i].Start,
+			EventEnd:      hours[coldest].Start,
+			DurationHours: int(hours[coldest].Start.Sub(hours[i].Start).Hours()),
+			PeakTime:      &peakTime,
+			PeakValue:     &peak,
+			Change:        &change,
+			Description:   fmt.Sprintf("Temperature falls %.1f°C in %d hours", drop, int(hours[coldest].Start.Sub(hours[i].Start).Hours())),
+		})
+		i = coldest
+	}
+	return events
+}
+
+func listForecastEvent(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	thresholds, err := getEventThresholds(d)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now().UTC().Truncate(time.Hour)
+	end := start.Add(time.Duration(thresholds.Hours) * time.Hour)
+	type Row struct {
+		forecastEvent
+		eventThresholds
+		queryLocation
+		Units unitSystem `json:"units"`
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.HourlyForecastRange(ctx, location.Latitude, location.Longitude, start, end)
+		if err != nil {
+			return nil, err
+		}
+		converter, err := newUnitConverter(weather.HourlyForecast.Metadata, units)
+		if err != nil {
+			return nil, err
+		}
+		var hours []eventHour
+		for _, hour := range weather.HourlyForecast.Hours {
+			if t := parseTime(hour.ForecastStart); t != nil {
+				hours = append(hours, eventHour{
+					Start:   *t,
+					Metric:  converter.toMetric().hourWeatherConditions(hour),
+					Display: converter.hourWeatherConditions(hour),
+				})
+			}
+		}
+		var rows []interface{}
+		for _, event := range detectForecastEvents(hours, thresholds) {
+			rows = append(rows, Row{forecastEvent: event, eventThresholds: thresholds, queryLocation: location, Units: units})
+		}
+		return rows, nil
+	})
+	return nil, err
+}
@@ -0,0 +1,197 @@
+package weatherkit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var eventTestStart = time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+
+// eventHours returns consecutive metric hours from eventTestStart with the
+// given JSON conditions.
+func eventHours(t *testing.T, conditions string) []eventHour {
+	t.Helper()
+	var decoded []HourWeatherConditions
+	if err := json.Unmarshal([]byte(conditions), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	hours := make([]eventHour, len(decoded))
+	for i, h := range decoded {
+		hours[i] = eventHour{Start: eventTestStart.Add(time.Duration(i) * time.Hour), Metric: h, Display: h}
+	}
+	return hours
+}
+
+// eventSummary is the part of an event compared by the tests, with the
+// start and end as hours from eventTestStart.
+type eventSummary struct {
+	eventType  string
+	start, end int
+	peak       float64
+}
+
+func summarizeEvents(events []forecastEvent) []eventSummary {
+	var summaries []eventSummary
+	for _, e := range events {
+		s := eventSummary{
+			eventType: e.EventType,
+			start:     int(e.EventStart.Sub(eventTestStart).Hours()),
+			end:       int(e.EventEnd.Sub(eventTestStart).Hours()),
+		}
+		if e.PeakValue != nil {
+			s.peak = *e.PeakValue
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+func TestDetectForecastEvents(t *testing.T) {
+	thresholds := defaultEventThresholds
+	thresholds.FreezeTemperature = -0.7
+	thresholds.PrecipitationThreshold = 0.7
+	thresholds.PressureDrop = 2.3
+	tests := []struct {
+		name  string
+		hours string
+		want  []eventSummary
+	}{
+		{
+			name: "freeze at the threshold",
+			hours: `[
+				{"temperature": 1}, {"temperature": -0.7}, {"temperature": -2}, {"temperature": -0.69}, {"temperature": -3}
+			]`,
+			// -0.69 is above the threshold and ends the run, and only the
+			// first run is reported.
+			want: []eventSummary{{eventFirstFreeze, 1, 3, -2}},
+		},
+		{
+			name: "precipitation runs at the window edges",
+			hours: `[
+				{"precipitationAmount": 0.7}, {"precipitationAmount": 1.5}, {"precipitationAmount": 0.69}, {}, {"precipitationAmount": 0.7}
+			]`,
+			want: []eventSummary{{eventPrecipitation, 0, 2, 1.5}, {eventPrecipitation, 4, 5, 0.7}},
+		},
+		{
+			name: "gusts at the threshold",
+			hours: `[
+				{"windGust": 50}, {"windGust": 49.9}, {}, {"windGust": 50}, {"windGust": 62}
+			]`,
+			want: []eventSummary{{eventWindGust, 0, 1, 50}, {eventWindGust, 3, 5, 62}},
+		},
+		{
+			name: "pressure falls by the threshold",
+			hours: `[
+				{"pressure": 1011.0, "pressureTrend": "falling"},
+				{"pressure": 1009.5, "pressureTrend": "falling"},
+				{"pressure": 1008.7, "pressureTrend": "falling"},
+				{"pressure": 1008.9, "pressureTrend": "steady"},
+				{"pressure": 1008.9, "pressureTrend": "falling"},
+				{"pressure": 1006.7, "pressureTrend": "falling"}
+			]`,
+			// The second run falls 2.2 mb, short of the threshold.
+			want: []eventSummary{{eventPressureFall, 0, 3, 1008.7}},
+		},
+		{
+			name: "missing values",
+			hours: `[
+				{"pressureTrend": "falling"}, {"precipitationType": "rain"}, {}, {"pressure": 1000, "pressureTrend": "falling"}
+			]`,
+		},
+	}
+	for _, tt := range tests {
+		got := summarizeEvents(detectForecastEvents(eventHours(t, tt.hours), thresholds))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: events = %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i].eventType != tt.want[i].eventType || got[i].start != tt.want[i].start || got[i].end != tt.want[i].end || float32(got[i].peak) != float32(tt.want[i].peak) {
+				t.Errorf("%s: event %d = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDetectForecastEventsPrecipitation(t *testing.T) {
+	hours := eventHours(t, `[
+		{"precipitationAmount": 0.2, "precipitationType": "snow"},
+		{"precipitationAmount": 0.4, "precipitationType": "rain"},
+		{"precipitationAmount": 0},
+		{"precipitationAmount": 0.1, "precipitationType": "clear"}
+	]`)
+	// An hour missing from the forecast splits a run.
+	hours = append(hours, eventHour{Start: hours[3].Start.Add(2 * time.Hour), Metric: hours[3].Metric, Display: hours[3].Display})
+	events := detectForecastEvents(hours, defaultEventThresholds)
+	if len(events) != 3 {
+		t.Fatalf("events = %+v, want 3 precipitation events", summarizeEvents(events))
+	}
+	if events[0].Change == nil || float32(*events[0].Change) != 0.6 || events[0].Description != "2 hours of snow" {
+		t.Errorf("first event total %v, description %q, want 0.6 and 2 hours of snow", events[0].Change, events[0].Description)
+	}
+	// A precipitation type of clear is reported as precipitation.
+	if !strings.HasSuffix(events[1].Description, " of precipitation") || events[1].DurationHours != 1 || events[2].DurationHours != 1 {
+		t.Errorf("events = %+v, want two one hour events of precipitation", events[1:])
+	}
+}
+
+func TestDetectTemperatureDrops(t *testing.T) {
+	thresholds := defaultEventThresholds
+	thresholds.TemperatureDrop = 10.7
+	thresholds.TemperatureDropHours = 6
+	tests := []struct {
+		name  string
+		hours string
+		want  []eventSummary
+	}{
+		{
+			name: "drop equal to the threshold",
+			hours: `[
+				{"temperature": 20}, {"temperature": 18}, {}, {"temperature": 12}, {"temperature": 9.3}, {"temperature": 15}
+			]`,
+			want: []eventSummary{{eventTemperatureDrop, 0, 4, 9.3}},
+		},
+		{
+			name: "drop just short of the threshold",
+			hours: `[
+				{"temperature": 20}, {"temperature": 9.4}
+			]`,
+		},
+		{
+			name: "drop at the end of the period",
+			hours: `[
+				{"temperature": 25}, {"temperature": 24}, {"temperature": 23}, {"temperature": 22},
+				{"temperature": 21}, {"temperature": 20}, {"temperature": 14}, {"temperature": 5}
+			]`,
+			// The fall to 5 is seven hours after the first hour, so the
+			// drop from 25 ends at 14 six hours later. The search resumes
+			// after it and finds no other drop.
+			want: []eventSummary{{eventTemperatureDrop, 0, 6, 14}},
+		},
+		{
+			name: "drops do not overlap",
+			hours: `[
+				{"temperature": 30}, {"temperature": 15}, {"temperature": 10}, {"temperature": -1}
+			]`,
+			want: []eventSummary{{eventTemperatureDrop, 0, 3, -1}},
+		},
+		{
+			name:  "missing temperatures",
+			hours: `[{}, {"temperature": 20}, {}, {}]`,
+		},
+	}
+	for _, tt := range tests {
+		got := summarizeEvents(detectTemperatureDrops(eventHours(t, tt.hours), thresholds))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: drops = %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i].start != tt.want[i].start || got[i].end != tt.want[i].end || float32(got[i].peak) != float32(tt.want[i].peak) {
+				t.Errorf("%s: drop %d = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
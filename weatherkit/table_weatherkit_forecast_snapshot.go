@@ -0,0 +1,180 @@
+package weatherkit
+
+import (
+	"context"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/snapshot"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+	"time"
+)
+
+func weatherKitForecastSnapshotColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns,
+		&plugin.Column{
+			Name:        "dataset",
+			Type:        proto.ColumnType_STRING,
+			Description: "The dataset of the stored forecast: forecastDaily or forecastHourly.",
+		},
+		&plugin.Column{
+			Name:        "read_time",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time the forecast was read, from its metadata.",
+		},
+		&plugin.Column{
+			Name:        "forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the forecast day or hour.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
+		},
+		&plugin.Column{
+			Name:        "forecast_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the forecast day or hour.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
+		},
+		&plugin.Column{
+			Name:        "lead_time_hours",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The hours from the read time to the start of the forecast day or hour.",
+		},
+		&plugin.Column{
+			Name:        "lead_days",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of calendar days from the day the forecast was read to the forecast day, in local solar time: 0 for the same day, 1 for the next day and so on.",
+		},
+		&plugin.Column{
+			Name:        "condition_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "The forecast condition.",
+		},
+		conditionDescriptionColumn(),
+		&plugin.Column{
+			Name:        "temperature",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast temperature at the start of the hour, for hourly forecasts, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		&plugin.Column{
+			Name:        "temperature_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast maximum temperature of the day, for daily forecasts, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		&plugin.Column{
+			Name:        "temperature_min",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast minimum temperature of the day, for daily forecasts, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		&plugin.Column{
+			Name:        "humidity",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast relative humidity, for hourly forecasts, from 0 to 1.",
+		},
+		&plugin.Column{
+			Name:        "precipitation_chance",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast chance of precipitation during the day or hour, from 0 to 1.",
+		},
+		&plugin.Column{
+			Name:        "precipitation_amount",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast amount of precipitation during the day or hour, in millimeters, or inches (imperial).",
+		},
+		&plugin.Column{
+			Name:        "wind_speed",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast wind speed, for hourly forecasts, in kilometers per hour, miles per hour (imperial), or meters per second (si).",
+		},
+		&plugin.Column{
+			Name:        "wind_gust",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The forecast maximum wind gust speed, for hourly forecasts, in kilometers per hour, miles per hour (imperial), or meters per second (si).",
+		},
+		&plugin.Column{
+			Name:        "forecast",
+			Type:        proto.ColumnType_JSON,
+			Description: "The full forecast day or hour.",
+		},
+		unitsColumn(),
+		&plugin.Column{
+			Name:        "metadata",
+			Type:        proto.ColumnType_JSON,
+			Description: "Descriptive information about the stored forecast.",
+		},
+	)
+}
+
+// snapshotKeyColumns returns the key columns of the tables that read the
+// snapshot store. Locations are optional, and every stored location is read
+// when none is given.
+func snapshotKeyColumns() plugin.KeyColumnSlice {
+	var keyColumns plugin.KeyColumnSlice
+	for _, c := range locationKeyColumns() {
+		keyColumns = append(keyColumns, &plugin.KeyColumn{Name: c.Name, Require: plugin.Optional})
+	}
+	return append(keyColumns,
+		&plugin.KeyColumn{Name: "read_time", Require: plugin.Optional, Operators: []string{"=", ">", ">=", "<", "<="}},
+		&plugin.KeyColumn{Name: "units", Require: plugin.Optional},
+	)
+}
+
+func tableWeatherKitForecastSnapshot() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_forecast_snapshot",
+		Description: "Daily and hourly forecasts recorded in the snapshot store, with their lead time.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(snapshotKeyColumns(),
+				&plugin.KeyColumn{Name: "dataset", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "forecast_start", Require: plugin.Optional, Operators: []string{"=", ">", ">=", "<", "<="}},
+			),
+			Hydrate: listForecastSnapshot,
+		},
+		Columns: weatherKitForecastSnapshotColumns(),
+	}
+}
+
+func listForecastSnapshot(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	datasets := []string{datasetDaily, datasetHourly}
+	if dataset := d.KeyColumnQualString("dataset"); dataset != "" {
+		if dataset != datasetDaily && dataset != datasetHourly {
+			return nil, fmt.Errorf("invalid dataset %q: expected %s or %s", dataset, datasetDaily, datasetHourly)
+		}
+		datasets = []string{dataset}
+	}
+	from, to := timeRangeQuals(d, "read_time", time.Time{}, time.Time{})
+	startFrom, startTo := timeRangeQuals(d, "forecast_start", time.Time{}, time.Time{})
+	type Row struct {
+		forecastSnapshot
+		queryLocation
+	}
+	err = scanSnapshots(ctx, d, datasets, from, to, func(location queryLocation, r snapshot.Record) error {
+		snapshots, err := decodeSnapshot(r, units)
+		if err != nil {
+			logger.Warn("listForecastSnapshot", "dataset", r.Dataset, "readTime", r.ReadTime, "error", err)
+			return nil
+		}
+		for _, s := range snapshots {
+			if start := parseTime(s.ForecastStart); start != nil {
+				if (!startFrom.IsZero() && start.Before(startFrom)) || (!startTo.IsZero() && start.After(startTo)) {
+					continue
+				}
+			}
+			d.StreamListItem(ctx, Row{forecastSnapshot: s, queryLocation: location})
+			if plugin.IsCancelled(ctx) {
+				logger.Trace("CANCELLED!")
+				return context.Canceled
+			}
+		}
+		return nil
+	})
+	if err == context.Canceled {
+		return nil, nil
+	}
+	return nil, err
+}
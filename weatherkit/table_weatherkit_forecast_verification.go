@@ -0,0 +1,554 @@
+package weatherkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/snapshot"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"math"
+	"sort"
+	"time"
+)
+
+// maxObservationOffset is the largest difference between the start of a
+// forecast hour and the time of the observation it is verified against.
+const maxObservationOffset = 30 * time.Minute
+
+// maxObservationGap is the longest period within a forecast day, including
+// at its start and end, without an observation for the day to be verified.
+const maxObservationGap = 3 * time.Hour
+
+func weatherKitForecastVerificationColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns,
+		&plugin.Column{
+			Name:        "dataset",
+			Type:        proto.ColumnType_STRING,
+			Description: "The dataset of the verified forecasts: forecastDaily or forecastHourly.",
+		},
+		&plugin.Column{
+			Name:        "lead_days",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of calendar days from the day the forecasts were read to the forecast day, in local solar time.",
+		},
+		&plugin.Column{
+			Name:        "samples",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of forecast days or hours with observations to verify against.",
+		},
+		&plugin.Column{
+			Name:        "first_read_time",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time the earliest verified forecast was read.",
+		},
+		&plugin.Column{
+			Name:        "last_read_time",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time the latest verified forecast was read.",
+		},
+		&plugin.Column{
+			Name:        "first_forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the earliest verified forecast day or hour.",
+		},
+		&plugin.Column{
+			Name:        "last_forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the latest verified forecast day or hour.",
+		},
+		&plugin.Column{
+			Name:        "temperature_bias",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean of the forecast minus the observed temperature of hourly forecasts. Positive values mean forecasts were too warm.",
+		},
+		&plugin.Column{
+			Name:        "temperature_mae",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean absolute error of the forecast temperature of hourly forecasts.",
+		},
+		&plugin.Column{
+			Name:        "temperature_rmse",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The root mean square error of the forecast temperature of hourly forecasts.",
+		},
+		&plugin.Column{
+			Name:        "temperature_max_bias",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean of the forecast minus the highest observed temperature of daily forecasts. Positive values mean forecasts were too warm.",
+		},
+		&plugin.Column{
+			Name:        "temperature_max_mae",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean absolute error of the forecast high temperature of daily forecasts.",
+		},
+		&plugin.Column{
+			Name:        "temperature_max_rmse",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The root mean square error of the forecast high temperature of daily forecasts.",
+		},
+		&plugin.Column{
+			Name:        "temperature_min_bias",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean of the forecast minus the lowest observed temperature of daily forecasts. Positive values mean forecasts were too warm.",
+		},
+		&plugin.Column{
+			Name:        "temperature_min_mae",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean absolute error of the forecast low temperature of daily forecasts.",
+		},
+		&plugin.Column{
+			Name:        "temperature_min_rmse",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The root mean square error of the forecast low temperature of daily forecasts.",
+		},
+		&plugin.Column{
+			Name:        "precipitation_brier_score",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The Brier score of the forecast precipitation chance, from 0 (perfect) to 1. Precipitation is observed in an hour or day when the precipitation intensity of an observation is above 0 or its condition is rain, snow or a storm.",
+		},
+		&plugin.Column{
+			Name:        "precipitation_observed_rate",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The fraction of verified days or hours with observed precipitation, for comparison with the Brier score of always forecasting the climatological rate.",
+		},
+		&plugin.Column{
+			Name:        "condition_hits",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of hours whose forecast condition category matched the observed one. Only hourly forecasts are counted.",
+		},
+		&plugin.Column{
+			Name:        "condition_misses",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of hours whose forecast condition category did not match the observed one. Only hourly forecasts are counted.",
+		},
+		&plugin.Column{
+			Name:        "condition_hit_rate",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The fraction of hours whose forecast condition category matched the observed one. Only hourly forecasts are counted.",
+		},
+		unitsColumn(),
+	)
+}
+
+func tableWeatherKitForecastVerification() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_forecast_verification",
+		Description: "Accuracy of recorded daily and hourly forecasts against recorded current weather, grouped by lead time. The current weather must be recorded regularly, for example every 15 minutes, for forecasts to be verified.",
+		List: &plugin.ListConfig{
+			KeyColumns: verificationKeyColumns(),
+			Hydrate:    listForecastVerification,
+		},
+		Columns: weatherKitForecastVerificationColumns(),
+	}
+}
+
+// verificationKeyColumns are the snapshot key columns, with the read time
+// range given by the first and last read times of the verified forecasts.
+func verificationKeyColumns() plugin.KeyColumnSlice {
+	var keyColumns plugin.KeyColumnSlice
+	for _, c := range snapshotKeyColumns() {
+		if c.Name != "read_time" {
+			keyColumns = append(keyColumns, c)
+		}
+	}
+	return append(keyColumns,
+		&plugin.KeyColumn{Name: "first_read_time", Require: plugin.Optional, Operators: []string{">", ">="}},
+		&plugin.KeyColumn{Name: "last_read_time", Require: plugin.Optional, Operators: []string{"<", "<="}},
+	)
+}
+
+// observation is a recorded current weather reading.
+type observation struct {
+	time                   time.Time
+	temperature            *float32
+	precipitationIntensity *float32
+	conditionCode          *string
+}
+
+// nearestObservation returns the observation closest to t within
+// maxObservationOffset. observations must be sorted by time.
+func nearestObservation(observations []observation, t time.Time) (observation, bool) {
+	i := sort.Search(len(observations), func(i int) bool { return !observations[i].time.Before(t) })
+	var best observation
+	found := false
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(observations) {
+			continue
+		}
+		offset := absDuration(observations[j].time.Sub(t))
+		if offset <= maxObservationOffset && (!found || offset < absDuration(best.time.Sub(t))) {
+			best, found = observations[j], true
+		}
+	}
+	return best, found
+}
+
+// dailyObservation summarizes the observations of a forecast day from start
+// to end. The day is verified only when every observation was made after the
+// forecast was read and none of the day is longer than maxObservationGap
+// without one. observations must be sorted by time.
+func dailyObservation(observations []observation, start, end, readTime time.Time) (high, low *float32, precipitation, ok bool) {
+	i := sort.Search(len(observations), func(i int) bool { return !observations[i].time.Before(start) })
+	last := start
+	for ; i < len(observations) && observations[i].time.Before(end); i++ {
+		o := observations[i]
+		if o.time.Before(readTime) || o.time.Sub(last) > maxObservationGap {
+			return nil, nil, false, false
+		}
+		last = o.time
+		if o.temperature != nil {
+			if high == nil || *o.temperature > *high {
+				high = o.temperature
+			}
+			if low == nil || *o.temperature < *low {
+				low = o.temperature
+			}
+		}
+		if precipitationObserved(o) {
+			precipitation = true
+		}
+	}
+	if end.Sub(last) > maxObservationGap {
+		return nil, nil, false, false
+	}
+	return high, low, precipitation, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// precipitationObserved reports whether an observation shows precipitation.
+func precipitationObserved(o observation) bool {
+	if o.precipitationIntensity != nil && *o.precipitationIntensity > 0 {
+		return true
+	}
+	if o.conditionCode != nil {
+		switch conditionCodeByCode[*o.conditionCode].Category {
+		case conditionCategoryRain, conditionCategorySnow, conditionCategoryStorm:
+			return true
+		}
+	}
+	return false
+}
+
+// errorSums accumulates the errors of a forecast value.
+type errorSums struct {
+	count int
+	sum   float64
+	abs   float64
+	sq    float64
+}
+
+func (e *errorSums) add(forecast, observed *float32) {
+	if forecast == nil || observed == nil {
+		return
+	}
+	d := float64(*forecast - *observed)
+	e.count++
+	e.sum += d
+	e.abs += math.Abs(d)
+	e.sq += d * d
+}
+
+// metrics returns the bias, mean absolute error and root mean square error,
+// or nils when there were no errors.
+func (e errorSums) metrics() (bias, mae, rmse *float64) {
+	if e.count == 0 {
+		return nil, nil, nil
+	}
+	n := float64(e.count)
+	b, m, r := e.sum/n, e.abs/n, math.Sqrt(e.sq/n)
+	return &b, &m, &r
+}
+
+// ForecastVerification holds the error metrics of the forecasts of a dataset
+// for a location and lead time.
+type ForecastVerification struct {
+	Dataset                   string     `json:"dataset"`
+	LeadDays                  int        `json:"leadDays"`
+	Samples                   int        `json:"samples"`
+	FirstReadTime             *time.Time `json:"firstReadTime,omitempty"`
+	LastReadTime              *time.Time `json:"lastReadTime,omitempty"`
+	FirstForecastStart        *time.Time `json:"firstForecastStart,omitempty"`
+	LastForecastStart         *time.Time `json:"lastForecastStart,omitempty"`
+	TemperatureBias           *float64   `json:"temperatureBias,omitempty"`
+	TemperatureMae            *float64   `json:"temperatureMae,omitempty"`
+	TemperatureRmse           *float64   `json:"temperatureRmse,omitempty"`
+	TemperatureMaxBias        *float64   `json:"temperatureMaxBias,omitempty"`
+	TemperatureMaxMae         *float64   `json:"temperatureMaxMae,omitempty"`
+	TemperatureMaxRmse        *float64   `json:"temperatureMaxRmse,omitempty"`
+	TemperatureMinBias        *float64   `json:"temperatureMinBias,omitempty"`
+	TemperatureMinMae         *float64   `json:"temperatureMinMae,omitempty"`
+	TemperatureMinRmse        *float64   `json:"temperatureMinRmse,omitempty"`
+	PrecipitationBrierScore   *float64   `json:"precipitationBrierScore,omitempty"`
+	PrecipitationObservedRate *float64   `json:"precipitationObservedRate,omitempty"`
+	ConditionHits             int        `json:"conditionHits"`
+	ConditionMisses           int        `json:"conditionMisses"`
+	ConditionHitRate          *float64   `json:"conditionHitRate,omitempty"`
+	Units                     unitSystem `json:"units"`
+
+	temperature        errorSums
+	temperatureMax     errorSums
+	temperatureMin     errorSums
+	precipitationCount int
+	brierSum           float64
+	observedSum        float64
+}
+
+// addSample counts a verified forecast day or hour.
+func (v *ForecastVerification) addSample(readTime, start time.Time) {
+	v.Samples++
+	if v.FirstReadTime == nil || readTime.Before(*v.FirstReadTime) {
+		v.FirstReadTime = &readTime
+	}
+	if v.LastReadTime == nil || readTime.After(*v.LastReadTime) {
+		v.LastReadTime = &readTime
+	}
+	if v.FirstForecastStart == nil || start.Before(*v.FirstForecastStart) {
+		v.FirstForecastStart = &start
+	}
+	if v.LastForecastStart == nil || start.After(*v.LastForecastStart) {
+		v.LastForecastStart = &start
+	}
+}
+
+// addPrecipitation verifies a forecast precipitation chance.
+func (v *ForecastVerification) addPrecipitation(chance *float32, precipitation bool) {
+	if chance == nil {
+		return
+	}
+	observed := 0.0
+	if precipitation {
+		observed = 1
+	}
+	p := float64(*chance)
+	v.precipitationCount++
+	v.brierSum += (p - observed) * (p - observed)
+	v.observedSum += observed
+}
+
+// addDay verifies a forecast day against the highest and lowest observed
+// temperatures and whether precipitation was observed.
+func (v *ForecastVerification) addDay(readTime, start time.Time, day DayWeatherConditions, high, low *float32, precipitation bool) {
+	v.addSample(readTime, start)
+	v.temperatureMax.add(day.TemperatureMax, high)
+	v.temperatureMin.add(day.TemperatureMin, low)
+	v.addPrecipitation(day.PrecipitationChance, precipitation)
+}
+
+// add verifies a forecast hour against an observation.
+func (v *ForecastVerification) add(readTime, start time.Time, hour HourWeatherConditions, o observation) {
+	v.addSample(readTime, start)
+	v.temperature.add(hour.Temperature, o.temperature)
+	v.addPrecipitation(hour.PrecipitationChance, precipitationObserved(o))
+	if hour.ConditionCode != nil && o.conditionCode != nil {
+		forecast, ok1 := conditionCodeByCode[*hour.ConditionCode]
+		observed, ok2 := conditionCodeByCode[*o.conditionCode]
+		if ok1 && ok2 {
+			if forecast.Category == observed.Category {
+				v.ConditionHits++
+			} else {
+				v.ConditionMisses++
+			}
+		}
+	}
+}
+
+// finish computes the metrics from the sums.
+func (v *ForecastVerification) finish() {
+	mean := func(sum float64, n int) *float64 {
+		if n == 0 {
+			return nil
+		}
+		m := sum / float64(n)
+		return &m
+	}
+	v.TemperatureBias, v.TemperatureMae, v.TemperatureRmse = v.temperature.metrics()
+	v.TemperatureMaxBias, v.TemperatureMaxMae, v.TemperatureMaxRmse = v.temperatureMax.metrics()
+	v.TemperatureMinBias, v.TemperatureMinMae, v.TemperatureMinRmse = v.temperatureMin.metrics()
+	v.PrecipitationBrierScore = mean(v.brierSum, v.precipitationCount)
+	v.PrecipitationObservedRate = mean(v.observedSum, v.precipitationCount)
+	v.ConditionHitRate = mean(float64(v.ConditionHits), v.ConditionHits+v.ConditionMisses)
+}
+
+func listForecastVerification(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := timeRangeQuals(d, "first_read_time", time.Time{}, time.Time{})
+	_, to := timeRangeQuals(d, "last_read_time", time.Time{}, time.Time{})
+	verifications, err := verifyForecasts(ctx, d, units, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range verifications {
+		d.StreamListItem(ctx, v)
+		if plugin.IsCancelled(ctx) {
+			logger.Trace("CANCELLED!")
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// locationVerification is the verification of the forecasts of a dataset
+// for a location and lead time.
+type locationVerification struct {
+	ForecastVerification
+	queryLocation
+}
+
+// verifyForecasts verifies the daily and hourly forecasts read within the
+// range against the observations recorded later, returning the metrics for
+// each location in the order first seen and each dataset and lead time in
+// increasing order.
+func verifyForecasts(ctx context.Context, d *plugin.QueryData, units unitSystem, from, to time.Time) ([]locationVerification, error) {
+	logger := plugin.Logger(ctx)
+
+	// Observations are read from the start of the range with no end, since
+	// forecasts are verified against later observations.
+	observations := map[string][]observation{}
+	err := scanSnapshots(ctx, d, []string{datasetCurrent}, from, time.Time{}, func(location queryLocation, r snapshot.Record) error {
+		var data CurrentWeatherData
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			logger.Warn("verifyForecasts", "dataset", r.Dataset, "readTime", r.ReadTime, "error", err)
+			return nil
+		}
+		converter, err := newUnitConverter(data.Metadata, units)
+		if err != nil {
+			return err
+		}
+		data = converter.currentWeather(data)
+		t := parseTime(data.AsOf)
+		if t == nil {
+			return nil
+		}
+		key := locationKey(location)
+		observations[key] = append(observations[key], observation{
+			time:                   *t,
+			temperature:            data.Temperature,
+			precipitationIntensity: data.PrecipitationIntensity,
+			conditionCode:          data.ConditionCode,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range observations {
+		sort.Slice(o, func(i, j int) bool { return o[i].time.Before(o[j].time) })
+	}
+
+	var locationOrder []string
+	verifications := map[string]map[verificationGroup]*ForecastVerification{}
+	locations := map[string]queryLocation{}
+	verification := func(key string, location queryLocation, dataset string, leadDays int) *ForecastVerification {
+		if verifications[key] == nil {
+			verifications[key] = map[verificationGroup]*ForecastVerification{}
+			locations[key] = location
+			locationOrder = append(locationOrder, key)
+		}
+		group := verificationGroup{dataset, leadDays}
+		v := verifications[key][group]
+		if v == nil {
+			v = &ForecastVerification{Dataset: dataset, LeadDays: leadDays, Units: units}
+			verifications[key][group] = v
+		}
+		return v
+	}
+	err = scanSnapshots(ctx, d, []string{datasetDaily, datasetHourly}, from, to, func(location queryLocation, r snapshot.Record) error {
+		key := locationKey(location)
+		if len(observations[key]) == 0 {
+			return nil
+		}
+		if r.Dataset == datasetDaily {
+			var data DailyForecastData
+			if err := json.Unmarshal(r.Data, &data); err != nil {
+				logger.Warn("verifyForecasts", "dataset", r.Dataset, "readTime", r.ReadTime, "error", err)
+				return nil
+			}
+			converter, err := newUnitConverter(data.Metadata, units)
+			if err != nil {
+				return err
+			}
+			for _, day := range data.Days {
+				start, end := parseTime(day.ForecastStart), parseTime(day.ForecastEnd)
+				if start == nil || end == nil {
+					continue
+				}
+				high, low, precipitation, ok := dailyObservation(observations[key], *start, *end, r.ReadTime)
+				if !ok {
+					continue
+				}
+				_, leadDays := leadTime(r.ReadTime, day.ForecastStart, r.Longitude, true)
+				verification(key, location, r.Dataset, *leadDays).addDay(r.ReadTime, *start, converter.dayWeatherConditions(day), high, low, precipitation)
+			}
+			return nil
+		}
+		var data HourlyForecastData
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			logger.Warn("verifyForecasts", "dataset", r.Dataset, "readTime", r.ReadTime, "error", err)
+			return nil
+		}
+		converter, err := newUnitConverter(data.Metadata, units)
+		if err != nil {
+			return err
+		}
+		for _, hour := range data.Hours {
+			start := parseTime(hour.ForecastStart)
+			if start == nil {
+				continue
+			}
+			o, ok := nearestObservation(observations[key], *start)
+			if !ok || o.time.Before(r.ReadTime) {
+				continue
+			}
+			_, leadDays := leadTime(r.ReadTime, hour.ForecastStart, r.Longitude, false)
+			verification(key, location, r.Dataset, *leadDays).add(r.ReadTime, *start, converter.hourWeatherConditions(hour), o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []locationVerification
+	for _, key := range locationOrder {
+		var groups []verificationGroup
+		for group := range verifications[key] {
+			groups = append(groups, group)
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].dataset != groups[j].dataset {
+				return groups[i].dataset < groups[j].dataset
+			}
+			return groups[i].leadDays < groups[j].leadDays
+		})
+		for _, group := range groups {
+			v := verifications[key][group]
+			v.finish()
+			results = append(results, locationVerification{ForecastVerification: *v, queryLocation: locations[key]})
+		}
+	}
+	return results, nil
+}
+
+// verificationGroup is a dataset and lead time whose forecasts are verified
+// together.
+type verificationGroup struct {
+	dataset  string
+	leadDays int
+}
+
+// locationKey identifies a location when grouping stored records.
+func locationKey(location queryLocation) string {
+	return fmt.Sprintf("%.6f,%.6f", location.Latitude, location.Longitude)
+}
@@ -0,0 +1,207 @@
+package weatherkit
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var verificationTestDay = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// verificationTime returns the time h hours and m minutes into verificationTestDay.
+func verificationTime(h, m int) time.Time {
+	return verificationTestDay.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+}
+
+func stringValue(s string) *string {
+	return &s
+}
+
+func TestNearestObservation(t *testing.T) {
+	observations := []observation{{time: verificationTime(10, 0)}, {time: verificationTime(10, 40)}, {time: verificationTime(12, 0)}}
+	tests := []struct {
+		t    time.Time
+		want time.Time
+		ok   bool
+	}{
+		{verificationTime(10, 15), verificationTime(10, 0), true},
+		{verificationTime(10, 25), verificationTime(10, 40), true},
+		// Ties go to the earlier observation.
+		{verificationTime(10, 20), verificationTime(10, 0), true},
+		// Up to maxObservationOffset either side.
+		{verificationTime(12, 30), verificationTime(12, 0), true},
+		{verificationTime(9, 30), verificationTime(10, 0), true},
+		{verificationTime(11, 20), time.Time{}, false},
+		{verificationTime(9, 29), time.Time{}, false},
+		{verificationTime(12, 31), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		o, ok := nearestObservation(observations, tt.t)
+		if ok != tt.ok || (ok && !o.time.Equal(tt.want)) {
+			t.Errorf("nearestObservation(%s) = %s, %v, want %s, %v", tt.t.Format("15:04"), o.time.Format("15:04"), ok, tt.want.Format("15:04"), tt.ok)
+		}
+	}
+	if _, ok := nearestObservation(nil, verificationTime(10, 0)); ok {
+		t.Error("nearestObservation with no observations found one")
+	}
+}
+
+func TestDailyObservation(t *testing.T) {
+	// Observations every three hours of the day.
+	every3h := func(temperatures ...float32) []observation {
+		var observations []observation
+		for i, temperature := range temperatures {
+			o := observation{time: verificationTime(3*i, 0)}
+			if !math.IsNaN(float64(temperature)) {
+				o.temperature = float32Value(temperature)
+			}
+			observations = append(observations, o)
+		}
+		return observations
+	}
+	missing := float32(math.NaN())
+	rainy := every3h(8, 7, 9, 14, 18, 17, 12, 10)
+	rainy[5].conditionCode = stringValue("Drizzle")
+	withGap := every3h(8, 7, 9, 14, 18, 17, 12, 10)
+	withGap = append(withGap[:3], withGap[4:]...)
+
+	tests := []struct {
+		name          string
+		observations  []observation
+		readTime      time.Time
+		high, low     *float32
+		precipitation bool
+		ok            bool
+	}{
+		{"whole day", every3h(8, 7, 9, 14, 18, 17, 12, 10), verificationTime(0, 0), float32Value(18), float32Value(7), false, true},
+		{"precipitation", rainy, verificationTime(-1, 0), float32Value(18), float32Value(7), true, true},
+		{"observation before the forecast", every3h(8, 7, 9, 14, 18, 17, 12, 10), verificationTime(1, 0), nil, nil, false, false},
+		{"gap within the day", withGap, verificationTime(0, 0), nil, nil, false, false},
+		{"gap at the end of the day", every3h(8, 7, 9, 14, 18, 17, 12), verificationTime(0, 0), nil, nil, false, false},
+		{"no observations", nil, verificationTime(0, 0), nil, nil, false, false},
+		// Observations without temperatures verify the day but give no
+		// high or low.
+		{"no temperatures", every3h(missing, missing, missing, missing, missing, missing, missing, missing), verificationTime(0, 0), nil, nil, false, true},
+	}
+	for _, tt := range tests {
+		high, low, precipitation, ok := dailyObservation(tt.observations, verificationTime(0, 0), verificationTime(24, 0), tt.readTime)
+		if ok != tt.ok || precipitation != tt.precipitation || !equalFloat32Ptr(high, tt.high) || !equalFloat32Ptr(low, tt.low) {
+			t.Errorf("%s: dailyObservation = %v, %v, %v, %v, want %v, %v, %v, %v", tt.name, formatFloat32Ptr(high), formatFloat32Ptr(low), precipitation, ok, formatFloat32Ptr(tt.high), formatFloat32Ptr(tt.low), tt.precipitation, tt.ok)
+		}
+	}
+}
+
+func equalFloat32Ptr(a, b *float32) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func formatFloat32Ptr(v *float32) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func TestErrorSums(t *testing.T) {
+	var e errorSums
+	if bias, mae, rmse := e.metrics(); bias != nil || mae != nil || rmse != nil {
+		t.Errorf("metrics without errors = %v, %v, %v, want nil", bias, mae, rmse)
+	}
+	// Errors of 2, -1 and 0; pairs missing either value are skipped.
+	e.add(float32Value(10), float32Value(8))
+	e.add(float32Value(12), float32Value(13))
+	e.add(float32Value(15), float32Value(15))
+	e.add(nil, float32Value(5))
+	e.add(float32Value(7), nil)
+	bias, mae, rmse := e.metrics()
+	if e.count != 3 || math.Abs(*bias-1.0/3) > 1e-9 || math.Abs(*mae-1) > 1e-9 || math.Abs(*rmse-math.Sqrt(5.0/3)) > 1e-9 {
+		t.Errorf("metrics = %d errors, bias %g, MAE %g, RMSE %g, want 3, 0.333, 1, 1.291", e.count, *bias, *mae, *rmse)
+	}
+}
+
+// formatMetric formats a metric for test failures.
+func formatMetric(metric *float64) string {
+	if metric == nil {
+		return "NULL"
+	}
+	return strconv.FormatFloat(*metric, 'g', 6, 64)
+}
+
+// near reports whether a metric is set and within 1e-6 of want.
+func near(metric *float64, want float64) bool {
+	return metric != nil && math.Abs(*metric-want) < 1e-6
+}
+
+func TestForecastVerification(t *testing.T) {
+	readTime := verificationTime(-6, 0)
+	v := ForecastVerification{Dataset: datasetHourly, LeadDays: 0}
+	hour := func(temperature, chance float32, condition string) HourWeatherConditions {
+		return HourWeatherConditions{Temperature: float32Value(temperature), PrecipitationChance: float32Value(chance), ConditionCode: stringValue(condition)}
+	}
+	obs := func(temperature float32, condition string) observation {
+		return observation{temperature: float32Value(temperature), conditionCode: stringValue(condition)}
+	}
+	// Drizzle and rain are the same category, so the first hour is a hit.
+	// The last hour's observed condition is unknown and is not counted.
+	v.add(readTime, verificationTime(1, 0), hour(10, 0.8, "Rain"), obs(8, "Drizzle"))
+	v.add(readTime, verificationTime(2, 0), hour(12, 0.3, "Clear"), obs(13, "Cloudy"))
+	v.add(readTime, verificationTime(3, 0), hour(15, 0, "MostlyClear"), obs(15, "Clear"))
+	v.add(readTime.Add(time.Hour), verificationTime(4, 0), hour(14, 1, "Snow"), obs(15, "Unknown"))
+	v.finish()
+
+	if v.Samples != 4 || !v.FirstReadTime.Equal(readTime) || !v.LastReadTime.Equal(readTime.Add(time.Hour)) || !v.FirstForecastStart.Equal(verificationTime(1, 0)) || !v.LastForecastStart.Equal(verificationTime(4, 0)) {
+		t.Errorf("%d samples read from %s to %s for %s to %s, want 4", v.Samples, v.FirstReadTime, v.LastReadTime, v.FirstForecastStart, v.LastForecastStart)
+	}
+	// Temperature errors of 2, -1, 0 and -1.
+	if !near(v.TemperatureBias, 0) || !near(v.TemperatureMae, 1) || !near(v.TemperatureRmse, math.Sqrt(6.0/4)) {
+		t.Errorf("temperature bias, MAE and RMSE = %s, %s, %s, want 0, 1, 1.225", formatMetric(v.TemperatureBias), formatMetric(v.TemperatureMae), formatMetric(v.TemperatureRmse))
+	}
+	// Squared errors of 0.04, 0.09, 0 and 1, with precipitation in one hour.
+	if !near(v.PrecipitationBrierScore, 1.13/4) || !near(v.PrecipitationObservedRate, 0.25) {
+		t.Errorf("Brier score and observed rate = %s, %s, want 0.2825, 0.25", formatMetric(v.PrecipitationBrierScore), formatMetric(v.PrecipitationObservedRate))
+	}
+	if v.ConditionHits != 2 || v.ConditionMisses != 1 || !near(v.ConditionHitRate, 2.0/3) {
+		t.Errorf("conditions = %d hits, %d misses, hit rate %s, want 2, 1, 0.667", v.ConditionHits, v.ConditionMisses, formatMetric(v.ConditionHitRate))
+	}
+	// Hourly forecasts have no daily highs and lows.
+	if v.TemperatureMaxBias != nil || v.TemperatureMinRmse != nil {
+		t.Errorf("daily temperature metrics = %s, %s, want NULL", formatMetric(v.TemperatureMaxBias), formatMetric(v.TemperatureMinRmse))
+	}
+}
+
+func TestForecastVerificationWithoutObservations(t *testing.T) {
+	// A lead day whose forecasts had no matching observed values, nor
+	// precipitation chances or known conditions, has NULL metrics rather
+	// than NaN.
+	v := ForecastVerification{Dataset: datasetDaily, LeadDays: 3}
+	v.addDay(verificationTime(-72, 0), verificationTime(0, 0), DayWeatherConditions{TemperatureMax: float32Value(20), TemperatureMin: float32Value(10)}, nil, nil, false)
+	v.add(verificationTime(-72, 0), verificationTime(1, 0), HourWeatherConditions{ConditionCode: stringValue("Clear")}, observation{})
+	v.finish()
+	for name, m := range map[string]*float64{
+		"temperature bias":   v.TemperatureBias,
+		"temperature MAE":    v.TemperatureMae,
+		"temperature RMSE":   v.TemperatureRmse,
+		"daily max bias":     v.TemperatureMaxBias,
+		"daily max MAE":      v.TemperatureMaxMae,
+		"daily max RMSE":     v.TemperatureMaxRmse,
+		"daily min bias":     v.TemperatureMinBias,
+		"daily min MAE":      v.TemperatureMinMae,
+		"daily min RMSE":     v.TemperatureMinRmse,
+		"Brier score":        v.PrecipitationBrierScore,
+		"observed rate":      v.PrecipitationObservedRate,
+		"condition hit rate": v.ConditionHitRate,
+	} {
+		if m != nil {
+			t.Errorf("%s = %s, want NULL", name, formatMetric(m))
+		}
+	}
+	if v.Samples != 2 {
+		t.Errorf("samples = %d, want 2", v.Samples)
+	}
+	// NaN cannot be encoded, so this also fails if any metric is NaN.
+	if _, err := json.Marshal(v); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,79 @@
+package weatherkit
+
+import (
+	"context"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+)
+
+func weatherKitGridCurrentWeatherColumns() []*plugin.Column {
+	columns := append(gridColumns(), reverseGeocodeColumns()...)
+	return append(columns, currentWeatherColumns()...)
+}
+
+func tableWeatherKitGridCurrentWeather() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_grid_current_weather",
+		Description: "WeatherKit Current Weather sampled on a grid over an area.",
+		List: &plugin.ListConfig{
+			KeyColumns: gridKeyColumns(),
+			Hydrate:    listGridCurrentWeather,
+		},
+		Columns: weatherKitGridCurrentWeatherColumns(),
+	}
+}
+
+func listGridCurrentWeather(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	cells, err := getGridCells(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	type Row struct {
+		gridCell
+		CurrentWeatherData
+		ComfortIndices
+		Units    unitSystem      `json:"units"`
+		Metadata WeatherMetadata `json:"metadata,omitempty"`
+	}
+	rows := make([]Row, len(cells))
+	err = forEachConcurrently(ctx, len(cells), getMaxConcurrency(d), func(ctx context.Context, i int) error {
+		weather, err := service.CurrentWeather(ctx, cells[i].Latitude, cells[i].Longitude)
+		if err != nil {
+			return err
+		}
+		converter, err := newUnitConverter(weather.CurrentWeather.Metadata, units)
+		if err != nil {
+			return err
+		}
+		metric := converter.toMetric().currentWeather(weather.CurrentWeather)
+		comfort := newComfortIndices(metric.Temperature, metric.Humidity, metric.TemperatureDewPoint, metric.WindSpeed)
+		rows[i] = Row{
+			gridCell:           cells[i],
+			CurrentWeatherData: converter.currentWeather(weather.CurrentWeather),
+			ComfortIndices:     converter.fromMetric().comfortIndices(comfort),
+			Units:              units,
+			Metadata:           weather.CurrentWeather.Metadata,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		d.StreamListItem(ctx, row)
+		if plugin.IsCancelled(ctx) {
+			logger.Trace("CANCELLED!")
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,83 @@
+package weatherkit
+
+import (
+	"context"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+)
+
+func weatherKitGridHourlyForecastColumns() []*plugin.Column {
+	columns := append(gridColumns(), reverseGeocodeColumns()...)
+	return append(columns, hourlyForecastColumns()...)
+}
+
+func tableWeatherKitGridHourlyForecast() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_grid_hourly_forecast",
+		Description: "WeatherKit Hourly Forecast sampled on a grid over an area.",
+		List: &plugin.ListConfig{
+			KeyColumns: gridKeyColumns(),
+			Hydrate:    listGridHourlyForecast,
+		},
+		Columns: weatherKitGridHourlyForecastColumns(),
+	}
+}
+
+func listGridHourlyForecast(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	cells, err := getGridCells(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	type Row struct {
+		gridCell
+		HourWeatherConditions
+		ComfortIndices
+		Units    unitSystem      `json:"units"`
+		Metadata WeatherMetadata `json:"metadata,omitempty"`
+	}
+	rows := make([][]Row, len(cells))
+	err = forEachConcurrently(ctx, len(cells), getMaxConcurrency(d), func(ctx context.Context, i int) error {
+		weather, err := service.HourlyForecast(ctx, cells[i].Latitude, cells[i].Longitude)
+		if err != nil {
+			return err
+		}
+		converter, err := newUnitConverter(weather.HourlyForecast.Metadata, units)
+		if err != nil {
+			return err
+		}
+		for _, hour := range weather.HourlyForecast.Hours {
+			metric := converter.toMetric().hourWeatherConditions(hour)
+			comfort := newComfortIndices(metric.Temperature, metric.Humidity, metric.TemperatureDewPoint, metric.WindSpeed)
+			rows[i] = append(rows[i], Row{
+				gridCell:              cells[i],
+				HourWeatherConditions: converter.hourWeatherConditions(hour),
+				ComfortIndices:        converter.fromMetric().comfortIndices(comfort),
+				Units:                 units,
+				Metadata:              weather.HourlyForecast.Metadata,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, cellRows := range rows {
+		for _, row := range cellRows {
+			d.StreamListItem(ctx, row)
+			if plugin.IsCancelled(ctx) {
+				logger.Trace("CANCELLED!")
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
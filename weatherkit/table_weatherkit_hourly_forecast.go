@@ -8,19 +8,14 @@ import (
 )
 
 func weatherKitHourlyForecastColumns() []*plugin.Column {
-	return []*plugin.Column{
-		{
-			Name:        "latitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the latitude of the coordinate between -90 and 90.",
-			Transform:   transform.FromQual("latitude"),
-		},
-		{
-			Name:        "longitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the longitude of the coordinate between -180 and 180.",
-			Transform:   transform.FromQual("longitude"),
-		},
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, hourlyForecastColumns()...)
+}
+
+// hourlyForecastColumns returns the hourly forecast columns that do not describe
+// the location, so that the grid table can share them.
+func hourlyForecastColumns() []*plugin.Column {
+	columns := []*plugin.Column{
 		{
 			Name:        "cloud_cover",
 			Type:        proto.ColumnType_DOUBLE,
@@ -31,6 +26,7 @@ func weatherKitHourlyForecastColumns() []*plugin.Column {
 			Type:        proto.ColumnType_STRING,
 			Description: "An enumeration value indicating the condition at the time.",
 		},
+		conditionDescriptionColumn(),
 		{
 			Name:        "daylight",
 			Type:        proto.ColumnType_BOOL,
@@ -40,6 +36,7 @@ func weatherKitHourlyForecastColumns() []*plugin.Column {
 			Name:        "forecast_start",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The starting date and time of the forecast.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "humidity",
@@ -59,7 +56,7 @@ func weatherKitHourlyForecastColumns() []*plugin.Column {
 		{
 			Name:        "pressure",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The sea-level air pressure, in millibars.",
+			Description: "The sea-level air pressure, in millibars, inches of mercury (imperial), or pascals (si).",
 		},
 		{
 			Name:        "pressure_trend",
@@ -69,22 +66,22 @@ func weatherKitHourlyForecastColumns() []*plugin.Column {
 		{
 			Name:        "snowfall_intensity",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The rate at which snow crystals are falling, in millimeters per hour.",
+			Description: "The rate at which snow crystals are falling, in millimeters per hour, or inches per hour (imperial).",
 		},
 		{
 			Name:        "temperature",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The temperature at the start of the hour, in degrees Celsius.",
+			Description: "The temperature at the start of the hour, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
 		{
 			Name:        "temperature_apparent",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The feels-like temperature when considering wind and humidity, at the start of the hour, in degrees Celsius.",
+			Description: "The feels-like temperature when considering wind and humidity, at the start of the hour, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
 		{
 			Name:        "temperature_dew_point",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The temperature at which relative humidity is 100% at the top of the hour, in degrees Celsius.",
+			Description: "The temperature at which relative humidity is 100% at the top of the hour, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
 		},
 		{
 			Name:        "uv_index",
@@ -94,7 +91,7 @@ func weatherKitHourlyForecastColumns() []*plugin.Column {
 		{
 			Name:        "visibility",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The distance at which terrain is visible at the start of the hour, in meters.",
+			Description: "The distance at which terrain is visible at the start of the hour, in meters, or miles (imperial).",
 		},
 		{
 			Name:        "wind_direction",
@@ -104,24 +101,28 @@ func weatherKitHourlyForecastColumns() []*plugin.Column {
 		{
 			Name:        "wind_gust",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The maximum wind gust speed during the hour, in kilometers per hour.",
+			Description: "The maximum wind gust speed during the hour, in kilometers per hour, miles per hour (imperial), or meters per second (si).",
 		},
 		{
 			Name:        "wind_speed",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The wind speed at the start of the hour, in kilometers per hour.",
+			Description: "The wind speed at the start of the hour, in kilometers per hour, miles per hour (imperial), or meters per second (si).",
 		},
 		{
 			Name:        "precipitation_amount",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The amount of precipitation forecasted to occur during period, in millimeters.",
+			Description: "The amount of precipitation forecasted to occur during period, in millimeters, or inches (imperial).",
 		},
-		{
+	}
+	columns = append(columns, comfortIndexColumns()...)
+	return append(columns,
+		unitsColumn(),
+		&plugin.Column{
 			Name:        "metadata",
 			Type:        proto.ColumnType_JSON,
 			Description: "Descriptive information about the weather data.",
 		},
-	}
+	)
 }
 
 func tableWeatherKitHourlyForecast() *plugin.Table {
@@ -129,7 +130,7 @@ func tableWeatherKitHourlyForecast() *plugin.Table {
 		Name:        "weatherkit_hourly_forecast",
 		Description: "WeatherKit Hourly Forecast.",
 		List: &plugin.ListConfig{
-			KeyColumns: plugin.AllColumns([]string{"latitude", "longitude"}),
+			KeyColumns: weatherKeyColumns(),
 			Hydrate:    listHourlyForecast,
 		},
 		Columns: weatherKitHourlyForecastColumns(),
@@ -143,23 +144,39 @@ func listHourlyForecast(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 		logger.Error("Invalid credentials.")
 		return nil, err
 	}
-	latitude := d.KeyColumnQuals["latitude"].GetDoubleValue()
-	longitude := d.KeyColumnQuals["longitude"].GetDoubleValue()
-	weather, _ := service.HourlyForecast(ctx, latitude, longitude)
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
 	type Row struct {
 		HourWeatherConditions
+		ComfortIndices
+		queryLocation
+		Units    unitSystem      `json:"units"`
 		Metadata WeatherMetadata `json:"metadata,omitempty"`
 	}
-	for _, hour := range weather.HourlyForecast.Hours {
-		row := Row{
-			HourWeatherConditions: hour,
-			Metadata:              weather.HourlyForecast.Metadata,
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.HourlyForecast(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			return nil, err
 		}
-		d.StreamListItem(ctx, row)
-		if plugin.IsCancelled(ctx) {
-			logger.Trace("CANCELLED!")
-			return nil, nil
+		converter, err := newUnitConverter(weather.HourlyForecast.Metadata, units)
+		if err != nil {
+			return nil, err
 		}
-	}
-	return nil, nil
+		var rows []interface{}
+		for _, hour := range weather.HourlyForecast.Hours {
+			metric := converter.toMetric().hourWeatherConditions(hour)
+			comfort := newComfortIndices(metric.Temperature, metric.Humidity, metric.TemperatureDewPoint, metric.WindSpeed)
+			rows = append(rows, Row{
+				HourWeatherConditions: converter.hourWeatherConditions(hour),
+				ComfortIndices:        converter.fromMetric().comfortIndices(comfort),
+				queryLocation:         location,
+				Units:                 units,
+				Metadata:              weather.HourlyForecast.Metadata,
+			})
+		}
+		return rows, nil
+	})
+	return nil, err
 }
@@ -0,0 +1,72 @@
+package weatherkit
+
+import (
+	"context"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+)
+
+func weatherKitLocationColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "name",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of the location, for use in the location column of other tables.",
+		},
+		{
+			Name:        "latitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "A numeric value indicating the latitude of the coordinate between -90 and 90.",
+		},
+		{
+			Name:        "longitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "A numeric value indicating the longitude of the coordinate between -180 and 180.",
+		},
+		{
+			Name:        "timezone",
+			Type:        proto.ColumnType_STRING,
+			Description: "The IANA time zone of the location, if configured.",
+		},
+		{
+			Name:        "country_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "The ISO country code of the location, if configured.",
+		},
+		{
+			Name:        "properties",
+			Type:        proto.ColumnType_JSON,
+			Description: "The other columns or GeoJSON properties of a location from locations_file.",
+		},
+		{
+			Name:        "source",
+			Type:        proto.ColumnType_STRING,
+			Description: "Where the location is defined: config for the locations option, or the path of locations_file.",
+		},
+	}
+}
+
+func tableWeatherKitLocation() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_location",
+		Description: "Named locations defined in the connection config or locations_file.",
+		List: &plugin.ListConfig{
+			Hydrate: listLocation,
+		},
+		Columns: weatherKitLocationColumns(),
+	}
+}
+
+func listLocation(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	locations, err := getNamedLocations(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	for _, location := range locations {
+		d.StreamListItem(ctx, location)
+		if plugin.IsCancelled(ctx) {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
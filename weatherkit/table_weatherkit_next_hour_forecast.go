@@ -8,28 +8,19 @@ import (
 )
 
 func weatherKitNextHourForecastColumns() []*plugin.Column {
-	return []*plugin.Column{
-		{
-			Name:        "latitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the latitude of the coordinate between -90 and 90.",
-			Transform:   transform.FromQual("latitude"),
-		},
-		{
-			Name:        "longitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "A numeric value indicating the longitude of the coordinate between -180 and 180.",
-			Transform:   transform.FromQual("longitude"),
-		},
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
 		{
 			Name:        "forecast_end",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time the forecast ends.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "forecast_start",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time the forecast starts.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "precipitation_chance",
@@ -39,19 +30,21 @@ func weatherKitNextHourForecastColumns() []*plugin.Column {
 		{
 			Name:        "precipitation_intensity",
 			Type:        proto.ColumnType_DOUBLE,
-			Description: "The precipitation intensity in millimeters per hour.",
+			Description: "The precipitation intensity in millimeters per hour, or inches per hour (imperial).",
 		},
 		{
 			Name:        "start_time",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The start time of the minute.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
+		unitsColumn(),
 		{
 			Name:        "metadata",
 			Type:        proto.ColumnType_JSON,
 			Description: "Descriptive information about the weather data.",
 		},
-	}
+	}...)
 }
 
 func tableWeatherKitNextHourForecast() *plugin.Table {
@@ -59,7 +52,7 @@ func tableWeatherKitNextHourForecast() *plugin.Table {
 		Name:        "weatherkit_next_hour_forecast",
 		Description: "WeatherKit Next Hour Forecast.",
 		List: &plugin.ListConfig{
-			KeyColumns: plugin.AllColumns([]string{"latitude", "longitude"}),
+			KeyColumns: weatherKeyColumns(),
 			Hydrate:    listNextHourForecast,
 		},
 		Columns: weatherKitNextHourForecastColumns(),
@@ -73,27 +66,39 @@ func listNextHourForecast(ctx context.Context, d *plugin.QueryData, _ *plugin.Hy
 		logger.Error("Invalid credentials.")
 		return nil, err
 	}
-	latitude := d.KeyColumnQuals["latitude"].GetDoubleValue()
-	longitude := d.KeyColumnQuals["longitude"].GetDoubleValue()
-	weather, _ := service.NextHourForecast(ctx, latitude, longitude)
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
 	type Row struct {
 		ForecastMinute
+		queryLocation
 		ForecastEnd   string          `json:"forecastEnd,omitempty"`
 		ForecastStart string          `json:"forecastStart,omitempty"`
+		Units         unitSystem      `json:"units"`
 		Metadata      WeatherMetadata `json:"metadata,omitempty"`
 	}
-	for _, minute := range weather.NextHourForecast.Minutes {
-		row := Row{
-			ForecastMinute: minute,
-			ForecastEnd:    weather.NextHourForecast.ForecastEnd,
-			ForecastStart:  weather.NextHourForecast.ForecastStart,
-			Metadata:       weather.NextHourForecast.Metadata,
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.NextHourForecast(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			return nil, err
 		}
-		d.StreamListItem(ctx, row)
-		if plugin.IsCancelled(ctx) {
-			logger.Trace("CANCELLED!")
-			return nil, nil
+		converter, err := newUnitConverter(weather.NextHourForecast.Metadata, units)
+		if err != nil {
+			return nil, err
 		}
-	}
-	return nil, nil
+		var rows []interface{}
+		for _, minute := range weather.NextHourForecast.Minutes {
+			rows = append(rows, Row{
+				ForecastMinute: converter.forecastMinute(minute),
+				queryLocation:  location,
+				ForecastEnd:    weather.NextHourForecast.ForecastEnd,
+				ForecastStart:  weather.NextHourForecast.ForecastStart,
+				Units:          units,
+				Metadata:       weather.NextHourForecast.Metadata,
+			})
+		}
+		return rows, nil
+	})
+	return nil, err
 }
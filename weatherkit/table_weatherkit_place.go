@@ -0,0 +1,114 @@
+package weatherkit
+
+import (
+	"context"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/gazetteer"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+)
+
+func weatherKitPlaceColumns() []*plugin.Column {
+	return []*plugin.Column{
+		{
+			Name:        "query",
+			Type:        proto.ColumnType_STRING,
+			Description: "The place name searched for, such as 'Ann Arbor, MI' or 'Paris, France'.",
+			Transform:   transform.FromQual("query"),
+		},
+		{
+			Name:        "name",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of the place.",
+		},
+		{
+			Name:        "ascii_name",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of the place in plain ASCII characters.",
+		},
+		{
+			Name:        "display_name",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of the place qualified by its admin1 code and country code.",
+			Transform:   transform.FromMethod("DisplayName"),
+		},
+		{
+			Name:        "admin1_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "The code of the first level administrative division of the place, such as the state abbreviation in the United States.",
+			Transform:   transform.FromField("Admin1Code").Transform(transform.NullIfZeroValue),
+		},
+		{
+			Name:        "country_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "The ISO country code of the place.",
+		},
+		{
+			Name:        "latitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "A numeric value indicating the latitude of the place between -90 and 90.",
+		},
+		{
+			Name:        "longitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "A numeric value indicating the longitude of the place between -180 and 180.",
+		},
+		{
+			Name:        "population",
+			Type:        proto.ColumnType_INT,
+			Description: "The population of the place.",
+		},
+		{
+			Name:        "timezone",
+			Type:        proto.ColumnType_STRING,
+			Description: "The IANA time zone of the place.",
+		},
+		{
+			Name:        "score",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "How closely the place matches the query, from 0 to 1. The place with the highest score is the one used by the place column of other tables.",
+		},
+	}
+}
+
+func tableWeatherKitPlace() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_place",
+		Description: "Places in the offline gazetteer, optionally matching a place name.",
+		List: &plugin.ListConfig{
+			Hydrate: listPlace,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "query", Require: plugin.Optional},
+			},
+		},
+		Columns: weatherKitPlaceColumns(),
+	}
+}
+
+func listPlace(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	var matches []gazetteer.Match
+	if query := d.KeyColumnQualString("query"); query != "" {
+		var err error
+		matches, err = gazetteer.Search(query, 0)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		places, err := gazetteer.Places()
+		if err != nil {
+			return nil, err
+		}
+		for _, place := range places {
+			matches = append(matches, gazetteer.Match{Place: place})
+		}
+	}
+	for _, match := range matches {
+		d.StreamListItem(ctx, match)
+		if plugin.IsCancelled(ctx) {
+			logger.Trace("CANCELLED!")
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
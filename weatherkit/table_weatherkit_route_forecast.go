@@ -0,0 +1,179 @@
+package weatherkit
+
+import (
+	"context"
+	"errors"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+	"time"
+)
+
+func weatherKitRouteForecastColumns() []*plugin.Column {
+	columns := []*plugin.Column{
+		{
+			Name:        "polyline",
+			Type:        proto.ColumnType_STRING,
+			Description: "The route as an encoded polyline with 5 decimal places of precision, as returned by Google Maps or OSRM.",
+			Transform:   transform.FromQual("polyline"),
+		},
+		{
+			Name:        "route",
+			Type:        proto.ColumnType_JSON,
+			Description: "The route as a GeoJSON LineString or a Feature with a LineString geometry.",
+			Transform:   transform.FromQual("route"),
+		},
+		{
+			Name:        "departure_time",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The time of departure from the start of the route. Defaults to now.",
+		},
+		{
+			Name:        "speed_kmh",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The average travel speed, in kilometers per hour. Defaults to 80.",
+		},
+		{
+			Name:        "interval_minutes",
+			Type:        proto.ColumnType_INT,
+			Description: "The travel time between sample points, in minutes. Defaults to 30.",
+		},
+		{
+			Name:        "sample_index",
+			Type:        proto.ColumnType_INT,
+			Description: "The position of the sample point along the route, counting from 0 at the start. The last sample point is the end of the route.",
+		},
+		{
+			Name:        "distance_km",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The distance along the route from the start to the sample point, in kilometers.",
+		},
+		{
+			Name:        "eta",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The estimated time of arrival at the sample point.",
+		},
+		{
+			Name:        "latitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The latitude of the sample point.",
+		},
+		{
+			Name:        "longitude",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The longitude of the sample point.",
+		},
+	}
+	columns = append(columns, reverseGeocodeColumns()...)
+	return append(columns, hourlyForecastColumns()...)
+}
+
+func tableWeatherKitRouteForecast() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_route_forecast",
+		Description: "WeatherKit Hourly Forecast at points along a route, for the hour each point is reached.",
+		List: &plugin.ListConfig{
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "polyline", Require: plugin.AnyOf},
+				{Name: "route", Require: plugin.AnyOf},
+				{Name: "departure_time", Require: plugin.Optional},
+				{Name: "speed_kmh", Require: plugin.Optional},
+				{Name: "interval_minutes", Require: plugin.Optional},
+				{Name: "units", Require: plugin.Optional},
+			},
+			Hydrate: listRouteForecast,
+		},
+		Columns: weatherKitRouteForecastColumns(),
+	}
+}
+
+// getRouteSamples returns the sample points of the route given by the
+// polyline or route quals.
+func getRouteSamples(_ context.Context, d *plugin.QueryData) ([]routeSample, error) {
+	var points []routePoint
+	var err error
+	if q, ok := d.KeyColumnQuals["route"]; ok {
+		points, err = parseLineString(q.GetJsonbValue())
+	} else if polyline := d.KeyColumnQualString("polyline"); polyline != "" {
+		points, err = decodePolyline(polyline)
+	} else {
+		return nil, errors.New("you must specify polyline or route")
+	}
+	if err != nil {
+		return nil, err
+	}
+	departure := time.Now().UTC().Truncate(time.Second)
+	if q, ok := d.KeyColumnQuals["departure_time"]; ok && q.GetTimestampValue() != nil {
+		departure = q.GetTimestampValue().AsTime()
+	}
+	speedKmh := float64(defaultRouteSpeedKmh)
+	if q, ok := d.KeyColumnQuals["speed_kmh"]; ok {
+		speedKmh = q.GetDoubleValue()
+	}
+	intervalMinutes := defaultRouteIntervalMinutes
+	if q, ok := d.KeyColumnQuals["interval_minutes"]; ok {
+		intervalMinutes = int(q.GetInt64Value())
+	}
+	return sampleRoute(points, departure, speedKmh, intervalMinutes)
+}
+
+func listRouteForecast(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := getRouteSamples(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	type Row struct {
+		routeSample
+		HourWeatherConditions
+		ComfortIndices
+		Units    unitSystem      `json:"units"`
+		Metadata WeatherMetadata `json:"metadata,omitempty"`
+	}
+	rows := make([]Row, len(samples))
+	err = forEachConcurrently(ctx, len(samples), getMaxConcurrency(d), func(ctx context.Context, i int) error {
+		sample := samples[i]
+		start := sample.Eta.Truncate(time.Hour)
+		weather, err := service.HourlyForecastRange(ctx, sample.Latitude, sample.Longitude, start, start.Add(time.Hour))
+		if err != nil {
+			return err
+		}
+		converter, err := newUnitConverter(weather.HourlyForecast.Metadata, units)
+		if err != nil {
+			return err
+		}
+		rows[i] = Row{
+			routeSample: sample,
+			Units:       units,
+			Metadata:    weather.HourlyForecast.Metadata,
+		}
+		// Samples beyond the forecast horizon keep empty weather columns.
+		if hour, ok := hourAt(weather.HourlyForecast.Hours, sample.Eta); ok {
+			metric := converter.toMetric().hourWeatherConditions(hour)
+			comfort := newComfortIndices(metric.Temperature, metric.Humidity, metric.TemperatureDewPoint, metric.WindSpeed)
+			rows[i].HourWeatherConditions = converter.hourWeatherConditions(hour)
+			rows[i].ComfortIndices = converter.fromMetric().comfortIndices(comfort)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		d.StreamListItem(ctx, row)
+		if plugin.IsCancelled(ctx) {
+			logger.Trace("CANCELLED!")
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
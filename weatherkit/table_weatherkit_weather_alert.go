@@ -8,19 +8,14 @@ import (
 )
 
 func weatherKitWeatherAlertColumns() []*plugin.Column {
-	return []*plugin.Column{
-		{
-			Name:        "latitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "The latitude of the desired location.",
-			Transform:   transform.FromQual("latitude"),
-		},
-		{
-			Name:        "longitude",
-			Type:        proto.ColumnType_DOUBLE,
-			Description: "The longitude of the desired location.",
-			Transform:   transform.FromQual("longitude"),
-		},
+	columns := locationColumns()
+	for _, column := range reverseGeocodeColumns() {
+		// Alerts have their own country_code, the country that issued the alert.
+		if column.Name != "country_code" {
+			columns = append(columns, column)
+		}
+	}
+	return append(columns, []*plugin.Column{
 		{
 			Name:        "area_id",
 			Type:        proto.ColumnType_STRING,
@@ -34,7 +29,8 @@ func weatherKitWeatherAlertColumns() []*plugin.Column {
 		{
 			Name:        "start_time",
 			Type:        proto.ColumnType_TIMESTAMP,
-			Description: "A human-readable name of the affected area.",
+			Description: "The time when the underlying weather event is projected to start.",
+			Transform:   transform.FromField("EventOnsetTime").Transform(toTimestamp),
 		},
 		{
 			Name:        "certainty",
@@ -60,21 +56,25 @@ func weatherKitWeatherAlertColumns() []*plugin.Column {
 			Name:        "effective_time",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time the event went into effect.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "end_time",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the underlying weather event is projected to end.",
+			Transform:   transform.FromField("EventEndTime").Transform(toTimestamp),
 		},
 		{
 			Name:        "event_onset_time",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the underlying weather event is projected to start.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "expire_time",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time when the event expires.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "id",
@@ -85,6 +85,7 @@ func weatherKitWeatherAlertColumns() []*plugin.Column {
 			Name:        "issued_time",
 			Type:        proto.ColumnType_TIMESTAMP,
 			Description: "The time that event was issued by the reporting agency.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
 		},
 		{
 			Name:        "responses",
@@ -111,7 +112,7 @@ func weatherKitWeatherAlertColumns() []*plugin.Column {
 			Type:        proto.ColumnType_JSON,
 			Description: "Descriptive information about the weather data.",
 		},
-	}
+	}...)
 }
 
 func tableWeatherKitWeatherAlert() *plugin.Table {
@@ -119,7 +120,7 @@ func tableWeatherKitWeatherAlert() *plugin.Table {
 		Name:        "weatherkit_weather_alert",
 		Description: "WeatherKit Weather Alert.",
 		List: &plugin.ListConfig{
-			KeyColumns: plugin.AllColumns([]string{"latitude", "longitude"}),
+			KeyColumns: locationKeyColumns(),
 			Hydrate:    listWeatherAlert,
 		},
 		Columns: weatherKitWeatherAlertColumns(),
@@ -133,25 +134,28 @@ func listWeatherAlert(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrat
 		logger.Error("Invalid credentials.")
 		return nil, err
 	}
-	latitude := d.KeyColumnQuals["latitude"].GetDoubleValue()
-	longitude := d.KeyColumnQuals["longitude"].GetDoubleValue()
-	weather, _ := service.WeatherAlerts(ctx, latitude, longitude)
-	logger.Debug("listWeatherAlert", "weather", weather)
 	type Row struct {
 		WeatherAlertSummary
+		queryLocation
 		Metadata WeatherMetadata `json:"metadata,omitempty"`
 	}
-	for _, alert := range weather.WeatherAlerts.Alerts {
-		row := Row{
-			WeatherAlertSummary: alert,
-			Metadata:            weather.WeatherAlerts.Metadata,
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.WeatherAlerts(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			return nil, err
 		}
-		logger.Debug("listWeatherAlert", "row", row)
-		d.StreamListItem(ctx, row)
-		if plugin.IsCancelled(ctx) {
-			logger.Trace("CANCELLED!")
-			return nil, nil
+		logger.Debug("listWeatherAlert", "weather", weather)
+		var rows []interface{}
+		for _, alert := range weather.WeatherAlerts.Alerts {
+			row := Row{
+				WeatherAlertSummary: alert,
+				queryLocation:       location,
+				Metadata:            weather.WeatherAlerts.Metadata,
+			}
+			logger.Debug("listWeatherAlert", "row", row)
+			rows = append(rows, row)
 		}
-	}
-	return nil, nil
+		return rows, nil
+	})
+	return nil, err
 }
@@ -0,0 +1,110 @@
+package weatherkit
+
+import (
+	"context"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
+)
+
+func weatherKitWeatherChangeColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "forecast_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the period of the change.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
+		},
+		{
+			Name:        "forecast_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the period of the change.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
+		},
+		{
+			Name:        "max_temperature_change",
+			Type:        proto.ColumnType_STRING,
+			Description: "The direction of change of the maximum daily temperature, such as inc, dec or steady.",
+		},
+		{
+			Name:        "min_temperature_change",
+			Type:        proto.ColumnType_STRING,
+			Description: "The direction of change of the minimum daily temperature, such as inc, dec or steady.",
+		},
+		{
+			Name:        "day_precipitation_change",
+			Type:        proto.ColumnType_STRING,
+			Description: "The direction of change of the daytime precipitation, such as inc, dec or steady.",
+		},
+		{
+			Name:        "night_precipitation_change",
+			Type:        proto.ColumnType_STRING,
+			Description: "The direction of change of the overnight precipitation, such as inc, dec or steady.",
+		},
+		{
+			Name:        "changes_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the period covered by the weather changes.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
+		},
+		{
+			Name:        "changes_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the period covered by the weather changes.",
+			Transform:   transform.FromGo().Transform(toTimestamp),
+		},
+		{
+			Name:        "metadata",
+			Type:        proto.ColumnType_JSON,
+			Description: "Descriptive information about the weather data.",
+		},
+	}...)
+}
+
+func tableWeatherKitWeatherChange() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_weather_change",
+		Description: "WeatherKit Weather Changes, the significant changes in temperature and precipitation over the coming days.",
+		List: &plugin.ListConfig{
+			KeyColumns: locationKeyColumns(),
+			Hydrate:    listWeatherChange,
+		},
+		Columns: weatherKitWeatherChangeColumns(),
+	}
+}
+
+func listWeatherChange(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	type Row struct {
+		WeatherChange
+		queryLocation
+		ChangesStart *string         `json:"changesStart,omitempty"`
+		ChangesEnd   *string         `json:"changesEnd,omitempty"`
+		Metadata     WeatherMetadata `json:"metadata,omitempty"`
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.WeatherChanges(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debug("listWeatherChange", "weather", weather)
+		var rows []interface{}
+		for _, change := range weather.WeatherChanges.Changes {
+			rows = append(rows, Row{
+				WeatherChange: change,
+				queryLocation: location,
+				ChangesStart:  weather.WeatherChanges.ForecastStart,
+				ChangesEnd:    weather.WeatherChanges.ForecastEnd,
+				Metadata:      weather.WeatherChanges.Metadata,
+			})
+		}
+		return rows, nil
+	})
+	return nil, err
+}
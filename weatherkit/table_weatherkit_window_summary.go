@@ -0,0 +1,321 @@
+package weatherkit
+
+import (
+	"context"
+	"fmt"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"time"
+)
+
+const (
+	// defaultWindowStartHour and defaultWindowEndHour bound the window when
+	// the query does not, covering a working day.
+	defaultWindowStartHour = 9
+	defaultWindowEndHour   = 17
+	// windowSummaryDefaultDays is the number of days returned when the query
+	// does not bound the date.
+	windowSummaryDefaultDays = 7
+)
+
+func weatherKitWindowSummaryColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	return append(columns, []*plugin.Column{
+		{
+			Name:        "date",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The local date the window starts on. Defaults to the next 7 days.",
+		},
+		{
+			Name:        "start_hour",
+			Type:        proto.ColumnType_INT,
+			Description: "The local hour the window starts at, from 0 to 23. Defaults to 9.",
+		},
+		{
+			Name:        "end_hour",
+			Type:        proto.ColumnType_INT,
+			Description: "The local hour the window ends at, from 1 to 24. Defaults to 17. A window with an end hour at or before its start hour ends on the next day.",
+		},
+		{
+			Name:        "timezone",
+			Type:        proto.ColumnType_STRING,
+			Description: "The IANA time zone of the local hours. Defaults to the time zone of the named location or place, then of the nearest place in the gazetteer, then to local mean solar time rounded to the hour.",
+		},
+		{
+			Name:        "window_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the window.",
+		},
+		{
+			Name:        "window_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the window.",
+		},
+		{
+			Name:        "hours",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of forecast hours in the window. It is less than the length of the window where the window runs past the end of the forecast.",
+		},
+		{
+			Name:        "temperature_min",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The lowest hourly temperature in the window, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "temperature_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The highest hourly temperature in the window, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "temperature_mean",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The mean hourly temperature in the window, in degrees Celsius, degrees Fahrenheit (imperial), or kelvin (si).",
+		},
+		{
+			Name:        "wind_gust_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The strongest wind gust in the window, in kilometers per hour, miles per hour (imperial), or meters per second (si).",
+		},
+		{
+			Name:        "precipitation_amount",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The total amount of precipitation forecast in the window, in millimeters, or inches (imperial).",
+		},
+		{
+			Name:        "precipitation_chance_max",
+			Type:        proto.ColumnType_DOUBLE,
+			Description: "The highest hourly chance of precipitation in the window, from 0 to 1.",
+		},
+		{
+			Name:        "condition_code",
+			Type:        proto.ColumnType_STRING,
+			Description: "The dominant condition of the window: the condition of the most hours, with ties going to the more severe condition.",
+		},
+		conditionDescriptionColumn(),
+		{
+			Name:        "condition_hours",
+			Type:        proto.ColumnType_INT,
+			Description: "The number of hours in the window with the dominant condition.",
+		},
+		unitsColumn(),
+	}...)
+}
+
+func tableWeatherKitWindowSummary() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_window_summary",
+		Description: "WeatherKit Hourly Forecast summarized over a window of local hours on each day.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(weatherKeyColumns(),
+				&plugin.KeyColumn{Name: "date", Require: plugin.Optional, Operators: []string{"=", ">", ">=", "<", "<="}},
+				&plugin.KeyColumn{Name: "start_hour", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "end_hour", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "timezone", Require: plugin.Optional},
+			),
+			Hydrate: listWindowSummary,
+		},
+		Columns: weatherKitWindowSummaryColumns(),
+	}
+}
+
+// windowSummary aggregates the forecast hours of a window.
+type windowSummary struct {
+	Date                   time.Time
+	StartHour              int
+	EndHour                int
+	Timezone               string
+	WindowStart            time.Time
+	WindowEnd              time.Time
+	Hours                  int
+	TemperatureMin         *float64
+	TemperatureMax         *float64
+	TemperatureMean        *float64
+	WindGustMax            *float64
+	PrecipitationAmount    *float64
+	PrecipitationChanceMax *float64
+	ConditionCode          *string
+	ConditionHours         int
+
+	temperatureSum   float64
+	temperatureCount int
+	conditions       map[string]int
+}
+
+// newWindowSummary returns an empty summary of the window starting on date,
+// a local date at midnight UTC, in the time zone tz.
+func newWindowSummary(date time.Time, startHour, endHour int, tz *time.Location) *windowSummary {
+	start := time.Date(date.Year(), date.Month(), date.Day(), startHour, 0, 0, 0, tz)
+	endDay := date.Day()
+	if endHour <= startHour {
+		endDay++
+	}
+	end := time.Date(date.Year(), date.Month(), endDay, endHour, 0, 0, 0, tz)
+	return &windowSummary{
+		Date:        date,
+		StartHour:   startHour,
+		EndHour:     endHour,
+		Timezone:    tz.String(),
+		WindowStart: start.UTC(),
+		WindowEnd:   end.UTC(),
+		conditions:  map[string]int{},
+	}
+}
+
+// contains reports whether an hour starting at t falls in the window.
+func (w *windowSummary) contains(t time.Time) bool {
+	return !t.Before(w.WindowStart) && t.Before(w.WindowEnd)
+}
+
+// updateMax replaces *max with v when v is larger, or when *max is unset.
+func updateMax(max **float64, v *float32) {
+	if v == nil {
+		return
+	}
+	if f := float64(*v); *max == nil || f > **max {
+		*max = &f
+	}
+}
+
+// updateMin replaces *min with v when v is smaller, or when *min is unset.
+func updateMin(min **float64, v *float32) {
+	if v == nil {
+		return
+	}
+	if f := float64(*v); *min == nil || f < **min {
+		*min = &f
+	}
+}
+
+func (w *windowSummary) add(hour HourWeatherConditions) {
+	w.Hours++
+	updateMin(&w.TemperatureMin, hour.Temperature)
+	updateMax(&w.TemperatureMax, hour.Temperature)
+	if hour.Temperature != nil {
+		w.temperatureSum += float64(*hour.Temperature)
+		w.temperatureCount++
+	}
+	updateMax(&w.WindGustMax, hour.WindGust)
+	updateMax(&w.PrecipitationChanceMax, hour.PrecipitationChance)
+	if hour.PrecipitationAmount != nil {
+		total := float64(*hour.PrecipitationAmount)
+		if w.PrecipitationAmount != nil {
+			total += *w.PrecipitationAmount
+		}
+		w.PrecipitationAmount = &total
+	}
+	if hour.ConditionCode != nil {
+		w.conditions[*hour.ConditionCode]++
+	}
+}
+
+func (w *windowSummary) finish() {
+	if w.temperatureCount > 0 {
+		mean := w.temperatureSum / float64(w.temperatureCount)
+		w.TemperatureMean = &mean
+	}
+	if code, hours := dominantCondition(w.conditions); hours > 0 {
+		w.ConditionCode = &code
+		w.ConditionHours = hours
+	}
+}
+
+// dominantCondition returns the condition code with the most hours, taking
+// the more severe condition on a tie, and its number of hours.
+func dominantCondition(hours map[string]int) (string, int) {
+	var dominant string
+	var most int
+	for code, n := range hours {
+		severity, dominantSeverity := conditionCodeByCode[code].Severity, conditionCodeByCode[dominant].Severity
+		if n > most || (n == most && (severity > dominantSeverity || (severity == dominantSeverity && code < dominant))) {
+			dominant, most = code, n
+		}
+	}
+	return dominant, most
+}
+
+// getWindowHours returns the start and end hours of the window from the
+// quals.
+func getWindowHours(d *plugin.QueryData) (int, int, error) {
+	startHour, endHour := defaultWindowStartHour, defaultWindowEndHour
+	if q, ok := d.KeyColumnQuals["start_hour"]; ok {
+		startHour = int(q.GetInt64Value())
+	}
+	if q, ok := d.KeyColumnQuals["end_hour"]; ok {
+		endHour = int(q.GetInt64Value())
+	}
+	if startHour < 0 || startHour > 23 {
+		return 0, 0, fmt.Errorf("invalid start_hour %d: must be between 0 and 23", startHour)
+	}
+	if endHour < 1 || endHour > 24 {
+		return 0, 0, fmt.Errorf("invalid end_hour %d: must be between 1 and 24", endHour)
+	}
+	return startHour, endHour, nil
+}
+
+func listWindowSummary(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	startHour, endHour, err := getWindowHours(d)
+	if err != nil {
+		return nil, err
+	}
+	type Row struct {
+		windowSummary
+		queryLocation
+		Units unitSystem `json:"units"`
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		tz, err := locationTimezone(location, d.KeyColumnQualString("timezone"))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().In(tz)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		from, to := timeRangeQuals(d, "date", today, today.AddDate(0, 0, windowSummaryDefaultDays-1))
+		var windows []*windowSummary
+		for date := from.UTC().Truncate(24 * time.Hour); !date.After(to); date = date.AddDate(0, 0, 1) {
+			windows = append(windows, newWindowSummary(date, startHour, endHour, tz))
+		}
+		if len(windows) == 0 {
+			return nil, nil
+		}
+		weather, err := service.HourlyForecastRange(ctx, location.Latitude, location.Longitude, windows[0].WindowStart, windows[len(windows)-1].WindowEnd)
+		if err != nil {
+			return nil, err
+		}
+		converter, err := newUnitConverter(weather.HourlyForecast.Metadata, units)
+		if err != nil {
+			return nil, err
+		}
+		for _, hour := range weather.HourlyForecast.Hours {
+			start := parseTime(hour.ForecastStart)
+			if start == nil {
+				continue
+			}
+			for _, w := range windows {
+				if w.contains(*start) {
+					w.add(converter.hourWeatherConditions(hour))
+				}
+			}
+		}
+		var rows []interface{}
+		for _, w := range windows {
+			// Windows beyond the forecast horizon have no hours.
+			if w.Hours == 0 {
+				continue
+			}
+			w.finish()
+			rows = append(rows, Row{windowSummary: *w, queryLocation: location, Units: units})
+		}
+		return rows, nil
+	})
+	return nil, err
+}
@@ -0,0 +1,221 @@
+package weatherkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"strings"
+	"time"
+)
+
+// workabilityDefaultHours is the number of hours evaluated when the query
+// does not bound forecast_start.
+const workabilityDefaultHours = 48
+
+func weatherKitWorkabilityColumns() []*plugin.Column {
+	columns := append(locationColumns(), reverseGeocodeColumns()...)
+	columns = append(columns, []*plugin.Column{
+		{
+			Name:        "rule_set",
+			Type:        proto.ColumnType_STRING,
+			Description: "The name of the rule set from the rule_sets connection option. Defaults to every rule set.",
+		},
+		{
+			Name:        "rules",
+			Type:        proto.ColumnType_STRING,
+			Description: "The conditions of the rule set, separated by semicolons. Conditions can also be given here instead of a rule_set, such as 'wind_gust <= 40; temperature > 5'.",
+		},
+		{
+			Name:        "workable",
+			Type:        proto.ColumnType_BOOL,
+			Description: "True if the hour meets every rule.",
+		},
+		{
+			Name:        "violated_rules",
+			Type:        proto.ColumnType_JSON,
+			Description: "The rules the hour does not meet. An hour without a value for a field does not meet rules on that field.",
+		},
+		{
+			Name:        "window_start",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The start of the run of consecutive workable hours containing the hour, or null if the hour is not workable.",
+		},
+		{
+			Name:        "window_end",
+			Type:        proto.ColumnType_TIMESTAMP,
+			Description: "The end of the run of consecutive workable hours containing the hour, or null if the hour is not workable.",
+		},
+		{
+			Name:        "window_hours",
+			Type:        proto.ColumnType_INT,
+			Description: "The length in hours of the run of consecutive workable hours containing the hour, or null if the hour is not workable.",
+		},
+	}...)
+	return append(columns, hourlyForecastColumns()...)
+}
+
+func tableWeatherKitWorkability() *plugin.Table {
+	return &plugin.Table{
+		Name:        "weatherkit_workability",
+		Description: "WeatherKit Hourly Forecast evaluated against rule sets, with the workable windows.",
+		List: &plugin.ListConfig{
+			KeyColumns: append(weatherKeyColumns(),
+				&plugin.KeyColumn{Name: "rule_set", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "rules", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "forecast_start", Require: plugin.Optional, Operators: []string{"=", ">", ">=", "<", "<="}},
+			),
+			Hydrate: listWorkability,
+		},
+		Columns: weatherKitWorkabilityColumns(),
+	}
+}
+
+// workability is the evaluation of a forecast hour against a rule set.
+type workability struct {
+	RuleSet       *string
+	Rules         string
+	Workable      bool
+	ViolatedRules []string
+	WindowStart   *time.Time
+	WindowEnd     *time.Time
+	WindowHours   *int
+}
+
+// getWorkabilityRuleSets returns the rule sets to evaluate: the conditions
+// given by the rules qual, the rule set named by the rule_set qual, or every
+// rule set in the connection config.
+func getWorkabilityRuleSets(d *plugin.QueryData) ([]ruleSet, error) {
+	name := d.KeyColumnQualString("rule_set")
+	if conditions := d.KeyColumnQualString("rules"); conditions != "" {
+		if name != "" {
+			return nil, errors.New("you must specify rule_set or rules, not both")
+		}
+		rules, err := parseRules(conditions)
+		if err != nil {
+			return nil, err
+		}
+		return []ruleSet{{Rules: rules}}, nil
+	}
+	ruleSets, err := getRuleSets(d)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		if len(ruleSets) == 0 {
+			return nil, errors.New("you must specify rules, or define rule_sets in the connection config")
+		}
+		return ruleSets, nil
+	}
+	for _, set := range ruleSets {
+		if strings.EqualFold(set.Name, name) {
+			// Report the name as given so that the rule_set qual matches.
+			set.Name = name
+			return []ruleSet{set}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown rule set %q: rule sets must be defined in the connection config", name)
+}
+
+// markWorkableWindows sets the window of each workable evaluation from the
+// run of consecutive workable hours containing it. starts holds the start of
+// each hour, in order.
+func markWorkableWindows(evaluations []workability, starts []time.Time) {
+	for i := 0; i < len(evaluations); {
+		if !evaluations[i].Workable {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(evaluations) && evaluations[j].Workable && starts[j].Sub(starts[j-1]) == time.Hour {
+			j++
+		}
+		start, end, hours := starts[i], starts[j-1].Add(time.Hour), j-i
+		for k := i; k < j; k++ {
+			evaluations[k].WindowStart, evaluations[k].WindowEnd, evaluations[k].WindowHours = &start, &end, &hours
+		}
+		i = j
+	}
+}
+
+func listWorkability(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logger := plugin.Logger(ctx)
+	service, err := connect(ctx, d)
+	if err != nil {
+		logger.Error("Invalid credentials.")
+		return nil, err
+	}
+	units, err := getUnitSystem(d)
+	if err != nil {
+		return nil, err
+	}
+	ruleSets, err := getWorkabilityRuleSets(d)
+	if err != nil {
+		return nil, err
+	}
+	start, end := forecastHourRange(d, workabilityDefaultHours)
+	type Row struct {
+		workability
+		HourWeatherConditions
+		ComfortIndices
+		queryLocation
+		Units    unitSystem      `json:"units"`
+		Metadata WeatherMetadata `json:"metadata,omitempty"`
+	}
+	err = streamLocations(ctx, d, func(ctx context.Context, location queryLocation) ([]interface{}, error) {
+		weather, err := service.HourlyForecastRange(ctx, location.Latitude, location.Longitude, start, end)
+		if err != nil {
+			return nil, err
+		}
+		converter, err := newUnitConverter(weather.HourlyForecast.Metadata, units)
+		if err != nil {
+			return nil, err
+		}
+		var hours, metricHours []HourWeatherConditions
+		var starts []time.Time
+		for _, hour := range weather.HourlyForecast.Hours {
+			if t := parseTime(hour.ForecastStart); t != nil {
+				hours = append(hours, hour)
+				metricHours = append(metricHours, converter.toMetric().hourWeatherConditions(hour))
+				starts = append(starts, *t)
+			}
+		}
+		var rows []interface{}
+		for _, set := range ruleSets {
+			rules := d.KeyColumnQualString("rules")
+			if rules == "" {
+				conditions := make([]string, len(set.Rules))
+				for i, r := range set.Rules {
+					conditions[i] = r.String()
+				}
+				rules = strings.Join(conditions, "; ")
+			}
+			var name *string
+			if set.Name != "" {
+				n := set.Name
+				name = &n
+			}
+			evaluations := make([]workability, len(hours))
+			for i, metric := range metricHours {
+				violated := violatedRules(set.Rules, metric)
+				evaluations[i] = workability{RuleSet: name, Rules: rules, Workable: len(violated) == 0, ViolatedRules: violated}
+			}
+			markWorkableWindows(evaluations, starts)
+			for i, hour := range hours {
+				metric := metricHours[i]
+				comfort := newComfortIndices(metric.Temperature, metric.Humidity, metric.TemperatureDewPoint, metric.WindSpeed)
+				rows = append(rows, Row{
+					workability:           evaluations[i],
+					HourWeatherConditions: converter.hourWeatherConditions(hour),
+					ComfortIndices:        converter.fromMetric().comfortIndices(comfort),
+					queryLocation:         location,
+					Units:                 units,
+					Metadata:              weather.HourlyForecast.Metadata,
+				})
+			}
+		}
+		return rows, nil
+	})
+	return nil, err
+}
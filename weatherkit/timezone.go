@@ -0,0 +1,43 @@
+package weatherkit
+
+import (
+	"fmt"
+	"github.com/ellisvalentiner/steampipe-plugin-weatherkit/weatherkit/gazetteer"
+	"math"
+	"time"
+
+	// Embed the time zone database so that zones load on hosts without one.
+	_ "time/tzdata"
+)
+
+// maxTimezoneDistanceKm is the furthest the nearest gazetteer place may be
+// from a location for its time zone to be used.
+const maxTimezoneDistanceKm = 250
+
+// locationTimezone returns the time zone used for the local days and hours
+// of a location. It is, in order, the zone named by the query, the zone of
+// the named location or place, the zone of the nearest place in the
+// gazetteer, or a fixed offset of local mean solar time rounded to the hour.
+func locationTimezone(location queryLocation, name string) (*time.Location, error) {
+	if name == "" {
+		name = location.timezone
+	}
+	if name != "" {
+		tz, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+		}
+		return tz, nil
+	}
+	place, distance, ok, err := gazetteer.Nearest(location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	if ok && distance <= maxTimezoneDistanceKm && place.Timezone != "" {
+		if tz, err := time.LoadLocation(place.Timezone); err == nil {
+			return tz, nil
+		}
+	}
+	hours := int(math.Round(normalizeLongitude(location.Longitude) / 15))
+	return time.FixedZone(fmt.Sprintf("UTC%+03d:00", hours), hours*3600), nil
+}
@@ -0,0 +1,235 @@
+package weatherkit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+)
+
+// unitSystem is the system of measurement used for the values returned in a row.
+type unitSystem string
+
+const (
+	unitsMetric   unitSystem = "metric"
+	unitsImperial unitSystem = "imperial"
+	unitsSI       unitSystem = "si"
+)
+
+// parseUnitSystem converts a user supplied unit system, including the
+// abbreviations WeatherKit uses in WeatherMetadata.Units, to a unitSystem.
+func parseUnitSystem(s string) (unitSystem, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "m", "metric":
+		return unitsMetric, nil
+	case "e", "us", "imperial":
+		return unitsImperial, nil
+	case "si":
+		return unitsSI, nil
+	default:
+		return "", fmt.Errorf("invalid units %q: must be one of metric, imperial, si", s)
+	}
+}
+
+// getUnitSystem returns the unit system requested by the `units` qual,
+// falling back to the connection config and then to metric. The qual must be
+// one of the names returned in the `units` column, since Postgres compares
+// it with the value returned in each row.
+func getUnitSystem(d *plugin.QueryData) (unitSystem, error) {
+	if units := d.KeyColumnQualString("units"); units != "" {
+		switch unitSystem(units) {
+		case unitsMetric, unitsImperial, unitsSI:
+			return unitSystem(units), nil
+		default:
+			return "", fmt.Errorf("invalid units %q: must be one of metric, imperial, si", units)
+		}
+	}
+	weatherKitConfig := GetConfig(d.Connection)
+	if weatherKitConfig.Units != nil {
+		return parseUnitSystem(*weatherKitConfig.Units)
+	}
+	return unitsMetric, nil
+}
+
+// unitConverter converts values reported in the source unit system to the
+// target unit system.
+type unitConverter struct {
+	source unitSystem
+	target unitSystem
+}
+
+// newUnitConverter returns a converter from the units reported in the
+// response metadata to the requested unit system.
+func newUnitConverter(metadata WeatherMetadata, target unitSystem) (unitConverter, error) {
+	source := unitsMetric
+	if metadata.Units != nil {
+		var err error
+		source, err = parseUnitSystem(*metadata.Units)
+		if err != nil {
+			return unitConverter{}, fmt.Errorf("unsupported response units: %w", err)
+		}
+	}
+	return unitConverter{source: source, target: target}, nil
+}
+
+// toMetric returns a converter from the source unit system to metric.
+func (u unitConverter) toMetric() unitConverter {
+	return unitConverter{source: u.source, target: unitsMetric}
+}
+
+// fromMetric returns a converter from metric to the target unit system, for
+// values derived from metric inputs.
+func (u unitConverter) fromMetric() unitConverter {
+	return unitConverter{source: unitsMetric, target: u.target}
+}
+
+// Each quantity is converted to metric and then to the target system.
+// Metric uses degrees Celsius, kilometers per hour, millimeters, millibars and meters.
+
+func (u unitConverter) convert(v *float32, toMetric func(float64, unitSystem) float64, fromMetric func(float64, unitSystem) float64) *float32 {
+	if v == nil || u.source == u.target {
+		return v
+	}
+	converted := float32(fromMetric(toMetric(float64(*v), u.source), u.target))
+	return &converted
+}
+
+func (u unitConverter) temperature(v *float32) *float32 {
+	return u.convert(v, func(v float64, s unitSystem) float64 {
+		switch s {
+		case unitsImperial:
+			return (v - 32) * 5 / 9
+		case unitsSI:
+			return v - 273.15
+		}
+		return v
+	}, func(v float64, s unitSystem) float64 {
+		switch s {
+		case unitsImperial:
+			return v*9/5 + 32
+		case unitsSI:
+			return v + 273.15
+		}
+		return v
+	})
+}
+
+func (u unitConverter) speed(v *float32) *float32 {
+	return u.convert(v, func(v float64, s unitSystem) float64 {
+		switch s {
+		case unitsImperial:
+			return v * 1.609344
+		case unitsSI:
+			return v * 3.6
+		}
+		return v
+	}, func(v float64, s unitSystem) float64 {
+		switch s {
+		case unitsImperial:
+			return v / 1.609344
+		case unitsSI:
+			return v / 3.6
+		}
+		return v
+	})
+}
+
+// precipitation converts precipitation and snowfall depths and rates.
+func (u unitConverter) precipitation(v *float32) *float32 {
+	return u.convert(v, func(v float64, s unitSystem) float64 {
+		if s == unitsImperial {
+			return v * 25.4
+		}
+		return v
+	}, func(v float64, s unitSystem) float64 {
+		if s == unitsImperial {
+			return v / 25.4
+		}
+		return v
+	})
+}
+
+func (u unitConverter) pressure(v *float32) *float32 {
+	return u.convert(v, func(v float64, s unitSystem) float64 {
+		switch s {
+		case unitsImperial:
+			return v * 33.8639
+		case unitsSI:
+			return v / 100
+		}
+		return v
+	}, func(v float64, s unitSystem) float64 {
+		switch s {
+		case unitsImperial:
+			return v / 33.8639
+		case unitsSI:
+			return v * 100
+		}
+		return v
+	})
+}
+
+func (u unitConverter) distance(v *float32) *float32 {
+	return u.convert(v, func(v float64, s unitSystem) float64 {
+		if s == unitsImperial {
+			return v * 1609.344
+		}
+		return v
+	}, func(v float64, s unitSystem) float64 {
+		if s == unitsImperial {
+			return v / 1609.344
+		}
+		return v
+	})
+}
+
+func (u unitConverter) currentWeather(c CurrentWeatherData) CurrentWeatherData {
+	c.PrecipitationIntensity = u.precipitation(c.PrecipitationIntensity)
+	c.Pressure = u.pressure(c.Pressure)
+	c.Temperature = u.temperature(c.Temperature)
+	c.TemperatureApparent = u.temperature(c.TemperatureApparent)
+	c.TemperatureDewPoint = u.temperature(c.TemperatureDewPoint)
+	c.Visibility = u.distance(c.Visibility)
+	c.WindGust = u.speed(c.WindGust)
+	c.WindSpeed = u.speed(c.WindSpeed)
+	return c
+}
+
+func (u unitConverter) dayPartForecast(p *DayPartForecast) *DayPartForecast {
+	if p == nil {
+		return nil
+	}
+	converted := *p
+	converted.PrecipitationAmount = u.precipitation(p.PrecipitationAmount)
+	converted.SnowfallAmount = u.precipitation(p.SnowfallAmount)
+	converted.WindSpeed = u.speed(p.WindSpeed)
+	return &converted
+}
+
+func (u unitConverter) dayWeatherConditions(c DayWeatherConditions) DayWeatherConditions {
+	c.DaytimeForecast = u.dayPartForecast(c.DaytimeForecast)
+	c.OvernightForecast = u.dayPartForecast(c.OvernightForecast)
+	c.PrecipitationAmount = u.precipitation(c.PrecipitationAmount)
+	c.SnowfallAmount = u.precipitation(c.SnowfallAmount)
+	c.TemperatureMax = u.temperature(c.TemperatureMax)
+	c.TemperatureMin = u.temperature(c.TemperatureMin)
+	return c
+}
+
+func (u unitConverter) hourWeatherConditions(c HourWeatherConditions) HourWeatherConditions {
+	c.PrecipitationAmount = u.precipitation(c.PrecipitationAmount)
+	c.Pressure = u.pressure(c.Pressure)
+	c.SnowfallIntensity = u.precipitation(c.SnowfallIntensity)
+	c.Temperature = u.temperature(c.Temperature)
+	c.TemperatureApparent = u.temperature(c.TemperatureApparent)
+	c.TemperatureDewPoint = u.temperature(c.TemperatureDewPoint)
+	c.Visibility = u.distance(c.Visibility)
+	c.WindGust = u.speed(c.WindGust)
+	c.WindSpeed = u.speed(c.WindSpeed)
+	return c
+}
+
+func (u unitConverter) forecastMinute(m ForecastMinute) ForecastMinute {
+	m.PrecipitationIntensity = u.precipitation(m.PrecipitationIntensity)
+	return m
+}
@@ -2,15 +2,24 @@ package weatherkit
 
 import (
 	"context"
+	"fmt"
+	"github.com/turbot/steampipe-plugin-sdk/v4/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v4/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v4/plugin/transform"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	baseUrl  = "weatherkit.apple.com"
 	language = "en"
+
+	defaultMaxConcurrency  = 5
+	maxCoordinatePrecision = 8
 )
 
 func connect(ctx context.Context, d *plugin.QueryData) (*Client, error) {
@@ -77,3 +86,194 @@ func connect(ctx context.Context, d *plugin.QueryData) (*Client, error) {
 
 	return client, nil
 }
+
+// getMaxConcurrency returns the maximum number of concurrent requests from
+// the connection config.
+func getMaxConcurrency(d *plugin.QueryData) int {
+	weatherKitConfig := GetConfig(d.Connection)
+	if weatherKitConfig.MaxConcurrency != nil && *weatherKitConfig.MaxConcurrency > 0 {
+		return *weatherKitConfig.MaxConcurrency
+	}
+	return defaultMaxConcurrency
+}
+
+// expandHome expands a leading ~/ in a path from the connection config to
+// the user's home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+// getCoordinatePrecision returns the number of decimal places to round
+// coordinates to from the connection config, or -1 to leave them unrounded.
+func getCoordinatePrecision(d *plugin.QueryData) (int, error) {
+	weatherKitConfig := GetConfig(d.Connection)
+	if weatherKitConfig.CoordinatePrecision == nil {
+		return -1, nil
+	}
+	precision := *weatherKitConfig.CoordinatePrecision
+	if precision < 0 || precision > maxCoordinatePrecision {
+		return 0, fmt.Errorf("invalid coordinate_precision %d: must be between 0 and %d", precision, maxCoordinatePrecision)
+	}
+	return precision, nil
+}
+
+// forEachConcurrently calls fn for each index from 0 to n-1, running at most
+// concurrency calls at a time. It stops starting new calls once a call fails
+// or the context is cancelled, and returns the first error.
+func forEachConcurrently(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+loop:
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-fnCtx.Done():
+			break loop
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(fnCtx, i); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// weatherKeyColumns returns the key columns shared by the weather tables.
+func weatherKeyColumns() plugin.KeyColumnSlice {
+	return append(locationKeyColumns(), &plugin.KeyColumn{Name: "units", Require: plugin.Optional})
+}
+
+// unitsColumn returns the column reporting the unit system of a row.
+func unitsColumn() *plugin.Column {
+	return &plugin.Column{
+		Name:        "units",
+		Type:        proto.ColumnType_STRING,
+		Description: "The unit system of the values: metric (default), imperial, or si. Defaults to the units connection option; as a qual, only these names are accepted.",
+	}
+}
+
+// timeRangeQuals returns the inclusive range of times given by the quals on
+// a timestamp column, defaulting to the given start and end where the query
+// does not bound the range.
+func timeRangeQuals(d *plugin.QueryData, column string, start, end time.Time) (time.Time, time.Time) {
+	if d.Quals[column] == nil {
+		return start, end
+	}
+	for _, q := range d.Quals[column].Quals {
+		ts := q.Value.GetTimestampValue()
+		if ts == nil {
+			continue
+		}
+		t := ts.AsTime()
+		switch q.Operator {
+		case "=":
+			start, end = t, t
+		case ">", ">=":
+			start = t
+		case "<", "<=":
+			end = t
+		}
+	}
+	return start, end
+}
+
+// dateRangeQuals returns the inclusive range of days given by the quals on a
+// date column. An unbounded range is the given number of days from today, an
+// unbounded end is that number of days from the start, and an unbounded start
+// is today, or that number of days before the end if it is in the past. Ranges
+// longer than maxDays are rejected.
+func dateRangeQuals(d *plugin.QueryData, column string, today time.Time, days int, maxDays int) (time.Time, time.Time, error) {
+	start, end := timeRangeQuals(d, column, time.Time{}, time.Time{})
+	span := time.Duration(days-1) * 24 * time.Hour
+	switch {
+	case start.IsZero() && end.IsZero():
+		start = today
+		end = today.Add(span)
+	case start.IsZero():
+		start = today
+		if end.Before(today) {
+			start = end.Add(-span)
+		}
+	case end.IsZero():
+		end = start.Add(span)
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	if end.Sub(start) >= time.Duration(maxDays)*24*time.Hour {
+		return start, end, fmt.Errorf("%s range exceeds %d days", column, maxDays)
+	}
+	return start, end, nil
+}
+
+// forecastHourRange returns the hours to request from the quals on the
+// forecast_start column. An unbounded start defaults to the current hour, and
+// an unbounded end to the given number of hours after the start.
+func forecastHourRange(d *plugin.QueryData, hours int) (time.Time, time.Time) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	start, end := timeRangeQuals(d, "forecast_start", time.Time{}, time.Time{})
+	switch {
+	case start.IsZero() && end.IsZero():
+		start = now
+		end = now.Add(time.Duration(hours) * time.Hour)
+	case start.IsZero():
+		start = now
+		if !end.After(now) {
+			start = end.Add(-time.Duration(hours) * time.Hour)
+		}
+	case end.IsZero():
+		end = start.Add(time.Duration(hours) * time.Hour)
+	}
+	// The hour containing the start is included.
+	return start.Truncate(time.Hour), end.Add(time.Hour).Truncate(time.Hour)
+}
+
+// parseTime parses an RFC 3339 timestamp from the WeatherKit API, returning
+// nil for missing or invalid values.
+func parseTime(s *string) *time.Time {
+	if s == nil || *s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// toTimestamp is a transform that parses WeatherKit timestamps so that
+// missing or empty values are returned as null rather than failing the row.
+func toTimestamp(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	switch v := d.Value.(type) {
+	case string:
+		return parseTime(&v), nil
+	case *string:
+		return parseTime(v), nil
+	default:
+		return d.Value, nil
+	}
+}
@@ -6,6 +6,7 @@ type Weather struct {
 	HourlyForecast   HourlyForecastData         `json:"forecastHourly,omitempty"`
 	NextHourForecast NextHourForecastData       `json:"forecastNextHour,omitempty"`
 	WeatherAlerts    WeatherAlertCollectionData `json:"weatherAlerts,omitempty"`
+	WeatherChanges   WeatherChangesData         `json:"weatherChanges,omitempty"`
 }
 
 type CurrentWeatherData struct {
@@ -130,21 +131,38 @@ type WeatherAlertCollectionData struct {
 }
 
 type WeatherAlertSummary struct {
-	AreaId        *string   `json:"areaId,omitempty"`
-	AreaName      *string   `json:"areaName,omitempty"`
-	Certainty     *string   `json:"certainty,omitempty"`
-	CountryCode   *string   `json:"countryCode,omitempty"`
-	Description   *string   `json:"description,omitempty"`
-	DetailsUrl    *string   `json:"detailsUrl,omitempty"`
-	EffectiveTime *string   `json:"effectiveTime,omitempty"`
-	EventEndTime  *string   `json:"eventEndTime,omitempty"`
-	ExpireTime    *string   `json:"expireTime,omitempty"`
-	Id            *string   `json:"id,omitempty"`
-	IssuedTime    *string   `json:"issuedTime,omitempty"`
-	Responses     *[]string `json:"responses,omitempty"`
-	Severity      *string   `json:"severity,omitempty"`
-	Source        *string   `json:"source,omitempty"`
-	Urgency       *string   `json:"urgency,omitempty"`
+	AreaId         *string   `json:"areaId,omitempty"`
+	AreaName       *string   `json:"areaName,omitempty"`
+	Certainty      *string   `json:"certainty,omitempty"`
+	CountryCode    *string   `json:"countryCode,omitempty"`
+	Description    *string   `json:"description,omitempty"`
+	DetailsUrl     *string   `json:"detailsUrl,omitempty"`
+	EffectiveTime  *string   `json:"effectiveTime,omitempty"`
+	EventEndTime   *string   `json:"eventEndTime,omitempty"`
+	EventOnsetTime *string   `json:"eventOnsetTime,omitempty"`
+	ExpireTime     *string   `json:"expireTime,omitempty"`
+	Id             *string   `json:"id,omitempty"`
+	IssuedTime     *string   `json:"issuedTime,omitempty"`
+	Responses      *[]string `json:"responses,omitempty"`
+	Severity       *string   `json:"severity,omitempty"`
+	Source         *string   `json:"source,omitempty"`
+	Urgency        *string   `json:"urgency,omitempty"`
+}
+
+type WeatherChangesData struct {
+	Changes       []WeatherChange `json:"changes,omitempty"`
+	ForecastEnd   *string         `json:"forecastEnd,omitempty"`
+	ForecastStart *string         `json:"forecastStart,omitempty"`
+	Metadata      WeatherMetadata `json:"metadata,omitempty"`
+}
+
+type WeatherChange struct {
+	DayPrecipitationChange   *string `json:"dayPrecipitationChange,omitempty"`
+	ForecastEnd              *string `json:"forecastEnd,omitempty"`
+	ForecastStart            *string `json:"forecastStart,omitempty"`
+	MaxTemperatureChange     *string `json:"maxTemperatureChange,omitempty"`
+	MinTemperatureChange     *string `json:"minTemperatureChange,omitempty"`
+	NightPrecipitationChange *string `json:"nightPrecipitationChange,omitempty"`
 }
 
 type WeatherMetadata struct {
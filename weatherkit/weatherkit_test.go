@@ -0,0 +1,57 @@
+package weatherkit
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// testdata/weather_changes.json follows the WeatherChanges schema in Apple's
+// WeatherKit REST API reference. It is not a captured response; replace it
+// with one recorded from the API when the schema is in doubt.
+func TestDecodeWeatherChanges(t *testing.T) {
+	data, err := os.ReadFile("testdata/weather_changes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var weather Weather
+	if err := json.Unmarshal(data, &weather); err != nil {
+		t.Fatal(err)
+	}
+	changes := weather.WeatherChanges
+	if changes.ForecastStart == nil || *changes.ForecastStart != "2024-05-01T04:00:00Z" || changes.ForecastEnd == nil || *changes.ForecastEnd != "2024-05-03T04:00:00Z" {
+		t.Errorf("forecast period = %v to %v, want 2024-05-01T04:00:00Z to 2024-05-03T04:00:00Z", changes.ForecastStart, changes.ForecastEnd)
+	}
+	if changes.Metadata.ReadTime == nil || changes.Metadata.Units == nil || *changes.Metadata.Units != "m" {
+		t.Errorf("metadata = %+v, want the read time and metric units", changes.Metadata)
+	}
+	if changes.Metadata.AttributionUrl == nil {
+		t.Error("metadata attribution URL was not decoded")
+	}
+	if len(changes.Changes) != 2 {
+		t.Fatalf("decoded %d changes, want 2", len(changes.Changes))
+	}
+	value := func(s *string) string {
+		if s == nil {
+			return "<nil>"
+		}
+		return *s
+	}
+	want := [][6]string{
+		{"2024-05-01T04:00:00Z", "2024-05-02T04:00:00Z", "inc", "steady", "dec", "steady"},
+		{"2024-05-02T04:00:00Z", "2024-05-03T04:00:00Z", "dec", "dec", "inc", "inc"},
+	}
+	for i, c := range changes.Changes {
+		got := [6]string{
+			value(c.ForecastStart),
+			value(c.ForecastEnd),
+			value(c.MaxTemperatureChange),
+			value(c.MinTemperatureChange),
+			value(c.DayPrecipitationChange),
+			value(c.NightPrecipitationChange),
+		}
+		if got != want[i] {
+			t.Errorf("change %d = %v, want %v", i, got, want[i])
+		}
+	}
+}